@@ -0,0 +1,376 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+// chronicle writes a world's history as a book.
+type chronicle struct {
+	w         *lg.World
+	civ       int
+	site      int
+	byCentury bool
+	figures   int
+
+	// members of civ
+	members map[int]bool
+}
+
+func newChronicle(w *lg.World, civ, site int) *chronicle {
+	c := &chronicle{w: w, civ: civ, site: site, members: map[int]bool{}}
+	if civ != -1 {
+		for _, f := range w.Figures {
+			for _, l := range f.Entities {
+				if l.ID == civ && l.Type == "member" {
+					c.members[f.ID] = true
+				}
+			}
+		}
+	}
+	return c
+}
+
+// match returns true if e belongs in the chronicle.
+func (c *chronicle) match(e *lg.Event) bool {
+	if c.site != -1 && e.SiteID != c.site {
+		return false
+	}
+	if c.civ == -1 {
+		return true
+	}
+	for _, id := range e.EntityIDs() {
+		if id == c.civ {
+			return true
+		}
+	}
+	for _, id := range e.FigureIDs() {
+		if c.members[id] {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *chronicle) write(bw bookWriter, w io.Writer) error {
+	title := "A History of the World"
+	if c.w.Name != "" {
+		title = "A History of " + titleCase(c.w.Name)
+	}
+	subtitle := c.w.AltName
+	if e := c.w.Entity(c.civ); e != nil {
+		subtitle = "As told by " + titleCase(e.Name)
+	}
+	if s := c.w.Site(c.site); s != nil {
+		subtitle = "The Story of " + titleCase(s.Name)
+	}
+	bw.begin(w, title, subtitle)
+
+	var events []*lg.Event
+	for _, e := range c.w.Events {
+		if c.match(e) {
+			events = append(events, e)
+		}
+	}
+
+	// History chapters
+	for _, ch := range c.chapters(events) {
+		bw.chapter(ch.title)
+		year := ch.events[0].Year - 1
+		var para []string
+		for _, e := range ch.events {
+			if e.Year != year && len(para) > 0 {
+				bw.para(fmt.Sprintf("In the year %d, %s", year, strings.Join(para, " ")))
+				para = para[:0]
+			}
+			year = e.Year
+			para = append(para, c.sentence(e))
+		}
+		if len(para) > 0 {
+			bw.para(fmt.Sprintf("In the year %d, %s", year, strings.Join(para, " ")))
+		}
+	}
+
+	c.writeWars(bw)
+	c.writeFigures(bw, events)
+	c.writeSiteFalls(bw, events)
+	return bw.end()
+}
+
+type chronicleChapter struct {
+	title  string
+	events []*lg.Event
+}
+
+// chapters splits events into one chapter per era or century.
+func (c *chronicle) chapters(events []*lg.Event) []*chronicleChapter {
+	var chapters []*chronicleChapter
+	if !c.byCentury && len(c.w.Eras) > 0 {
+		for _, ee := range c.w.ByEra(events) {
+			chapters = append(chapters, &chronicleChapter{title: ee.Era.Name, events: ee.Events})
+		}
+		return chapters
+	}
+	var cur *chronicleChapter
+	century := -1
+	for _, e := range events {
+		if cur == nil || e.Year/100 != century {
+			century = e.Year / 100
+			cur = &chronicleChapter{title: fmt.Sprintf("Years %d to %d", century*100, century*100+99)}
+			chapters = append(chapters, cur)
+		}
+		cur.events = append(cur.events, e)
+	}
+	return chapters
+}
+
+// sentence renders e as a sentence.
+func (c *chronicle) sentence(e *lg.Event) string {
+	s := c.w.RenderEvent(e)
+	if s == "" {
+		return s
+	}
+	return capitalize(s) + "."
+}
+
+// entityName returns the name of an entity or a placeholder like RenderEvent's
+// if the ID is -1 or dangling.
+func (c *chronicle) entityName(id int) string {
+	if e := c.w.Entity(id); e != nil {
+		return e.Name
+	}
+	return fmt.Sprintf("unknown entity %d", id)
+}
+
+// capitalize upper cases the first letter of s.
+func capitalize(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// titleCase upper cases the first letter of every word in s.
+func titleCase(s string) string {
+	words := strings.Split(s, " ")
+	for i, w := range words {
+		words[i] = capitalize(w)
+	}
+	return strings.Join(words, " ")
+}
+
+func (c *chronicle) writeWars(bw bookWriter) {
+	var wars []*lg.Collection
+	for _, col := range c.w.Collections {
+		if col.Type != "war" {
+			continue
+		}
+		if c.civ != -1 && col.AggressorEntityID != c.civ && col.DefenderEntityID != c.civ {
+			continue
+		}
+		if c.site != -1 && !c.warAtSite(col) {
+			continue
+		}
+		wars = append(wars, col)
+	}
+	if len(wars) == 0 {
+		return
+	}
+
+	bw.chapter("Major Wars")
+	for _, war := range wars {
+		bw.section(titleCase(war.String()))
+		end := "continues to this day"
+		if war.EndYear != -1 {
+			end = fmt.Sprintf("ended in %d", war.EndYear)
+		}
+		bw.para(fmt.Sprintf("Waged by %s against %s, the war began in %d and %s.",
+			c.entityName(war.AggressorEntityID), c.entityName(war.DefenderEntityID), war.StartYear, end))
+		for _, battle := range c.w.SubCollections(war) {
+			p := fmt.Sprintf("In %d, %s", battle.StartYear, battle)
+			if s := c.w.Site(battle.SiteID); s != nil {
+				p += fmt.Sprintf(" was fought at %s", s)
+			} else {
+				p += " was fought"
+			}
+			if battle.Outcome != "" {
+				p += fmt.Sprintf(" and the %s", battle.Outcome)
+			}
+			bw.para(p + ".")
+		}
+	}
+}
+
+func (c *chronicle) warAtSite(war *lg.Collection) bool {
+	for _, sc := range c.w.SubCollections(war) {
+		if sc.SiteID == c.site {
+			return true
+		}
+	}
+	for _, e := range c.w.CollectionEvents(war) {
+		if e.SiteID == c.site {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFigures writes biographies of the figures involved in the most events.
+func (c *chronicle) writeFigures(bw bookWriter, events []*lg.Event) {
+	byFigure := map[int][]*lg.Event{}
+	for _, e := range events {
+		for _, id := range e.FigureIDs() {
+			if c.civ != -1 && !c.members[id] {
+				continue
+			}
+			byFigure[id] = append(byFigure[id], e)
+		}
+	}
+	ids := make([]int, 0, len(byFigure))
+	for id := range byFigure {
+		if c.w.Figure(id) != nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(byFigure[ids[i]]) == len(byFigure[ids[j]]) {
+			return ids[i] < ids[j]
+		}
+		return len(byFigure[ids[i]]) > len(byFigure[ids[j]])
+	})
+	if len(ids) > c.figures {
+		ids = ids[:c.figures]
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	bw.chapter("Notable Figures")
+	for _, id := range ids {
+		f := c.w.Figure(id)
+		bw.section(titleCase(f.Name))
+		bio := fmt.Sprintf("%s was a %s %s", titleCase(f.Name), strings.ToLower(f.Caste), strings.ToLower(f.Race))
+		if f.BirthYear != -1 {
+			bio += fmt.Sprintf(" born in %d", f.BirthYear)
+		}
+		if f.DiedIn != -1 {
+			bio += fmt.Sprintf(" who died in %d", f.DiedIn)
+		}
+		bw.para(bio + ".")
+		for _, e := range byFigure[id] {
+			bw.para(fmt.Sprintf("In %d, %s", e.Year, c.sentence(e)))
+		}
+	}
+}
+
+// writeSiteFalls writes the sites which were destroyed or taken over.
+func (c *chronicle) writeSiteFalls(bw bookWriter, events []*lg.Event) {
+	var falls []*lg.Event
+	for _, e := range events {
+		if e.Type == "destroyed site" || e.Type == "site taken over" {
+			falls = append(falls, e)
+		}
+	}
+	if len(falls) == 0 {
+		return
+	}
+
+	bw.chapter("Fallen Sites")
+	bySite := map[int][]*lg.Event{}
+	var sites []int
+	for _, e := range falls {
+		if _, ok := bySite[e.SiteID]; !ok {
+			sites = append(sites, e.SiteID)
+		}
+		bySite[e.SiteID] = append(bySite[e.SiteID], e)
+	}
+	for _, id := range sites {
+		title := fmt.Sprintf("Site %d", id)
+		if s := c.w.Site(id); s != nil {
+			title = titleCase(s.Name)
+		}
+		bw.section(title)
+		for _, e := range bySite[id] {
+			bw.para(fmt.Sprintf("In %d, %s", e.Year, c.sentence(e)))
+		}
+	}
+}
+
+// bookWriter writes a chronicle in a specific format.
+type bookWriter interface {
+	begin(w io.Writer, title, subtitle string)
+	chapter(title string)
+	section(title string)
+	para(text string)
+	end() error
+}
+
+type markdownWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *markdownWriter) printf(format string, args ...interface{}) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+func (m *markdownWriter) begin(w io.Writer, title, subtitle string) {
+	m.w = w
+	m.printf("# %s\n\n", title)
+	if subtitle != "" {
+		m.printf("*%s*\n\n", subtitle)
+	}
+}
+
+func (m *markdownWriter) chapter(title string) { m.printf("## %s\n\n", title) }
+func (m *markdownWriter) section(title string) { m.printf("### %s\n\n", title) }
+func (m *markdownWriter) para(text string)     { m.printf("%s\n\n", text) }
+func (m *markdownWriter) end() error           { return m.err }
+
+type htmlWriter struct {
+	markdownWriter
+}
+
+func (h *htmlWriter) begin(w io.Writer, title, subtitle string) {
+	h.w = w
+	h.printf(`<!DOCTYPE html>
+<html>
+    <head>
+        <meta charset="utf-8">
+        <title>%s</title>
+        <style>
+            body { max-width: 40em; margin: auto; font-family: serif; line-height: 1.5; }
+            h1, h2, .subtitle { text-align: center; }
+            h2 { page-break-before: always; }
+        </style>
+    </head>
+    <body>
+        <h1>%s</h1>
+`, html.EscapeString(title), html.EscapeString(title))
+	if subtitle != "" {
+		h.printf("        <p class=\"subtitle\"><em>%s</em></p>\n", html.EscapeString(subtitle))
+	}
+}
+
+func (h *htmlWriter) chapter(title string) {
+	h.printf("        <h2>%s</h2>\n", html.EscapeString(title))
+}
+func (h *htmlWriter) section(title string) {
+	h.printf("        <h3>%s</h3>\n", html.EscapeString(title))
+}
+func (h *htmlWriter) para(text string) { h.printf("        <p>%s</p>\n", html.EscapeString(text)) }
+
+func (h *htmlWriter) end() error {
+	h.printf("    </body>\n</html>\n")
+	return h.err
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+func diffMain(args []string) {
+	fs := newFlagSet("diff")
+	sections := fs.String("sections", "", "comma separated sections to compare (default all)")
+	parseFlags(fs, args, 2)
+
+	var only []string
+	if *sections != "" {
+		only = strings.Split(*sections, ",")
+		for _, name := range only {
+			if !validSection(name) {
+				fmt.Fprintf(os.Stderr, "unknown section %q\n", name)
+				os.Exit(exitUsage)
+			}
+		}
+	}
+
+	old := load(fs.Args()[:1])
+	cur := load(fs.Args()[1:2])
+
+	w := bufio.NewWriter(os.Stdout)
+	changes := 0
+	for _, s := range dumpSections {
+		if len(only) > 0 && !contains(only, s.name) {
+			continue
+		}
+		changes += diffSection(w, s.name, reflect.ValueOf(s.records(old)), reflect.ValueOf(s.records(cur)))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing diff: %v\n", err)
+		os.Exit(exitIO)
+	}
+	if changes > 0 {
+		os.Exit(exitDiff)
+	}
+}
+
+// diffSection prints the records added, removed and changed between two
+// versions of a section and returns the number of differences.
+func diffSection(w *bufio.Writer, name string, old, cur reflect.Value) int {
+	oldRecs := keyedRecords(old)
+	curRecs := keyedRecords(cur)
+	var added, removed, changed int
+	for i := 0; i < cur.Len(); i++ {
+		rec := toRecord(cur.Index(i))
+		key := recordKey(rec)
+		prev, ok := oldRecs[key]
+		if !ok {
+			added++
+			fmt.Fprintf(w, "+ %s %s %s\n", name, key, recordName(rec))
+			continue
+		}
+		if fields := changedFields(prev, rec); len(fields) > 0 {
+			changed++
+			fmt.Fprintf(w, "~ %s %s %s: %s\n", name, key, recordName(rec), strings.Join(fields, ","))
+		}
+	}
+	for i := 0; i < old.Len(); i++ {
+		rec := toRecord(old.Index(i))
+		if _, ok := curRecs[recordKey(rec)]; !ok {
+			removed++
+			fmt.Fprintf(w, "- %s %s %s\n", name, recordKey(rec), recordName(rec))
+		}
+	}
+	if added+removed+changed > 0 {
+		fmt.Fprintf(w, "%s: %d added, %d removed, %d changed\n", name, added, removed, changed)
+	}
+	return added + removed + changed
+}
+
+func keyedRecords(records reflect.Value) map[string][]field {
+	out := make(map[string][]field, records.Len())
+	for i := 0; i < records.Len(); i++ {
+		rec := toRecord(records.Index(i))
+		out[recordKey(rec)] = rec
+	}
+	return out
+}
+
+// recordKey identifies a record by its id or, lacking one, its name.
+func recordKey(rec []field) string {
+	for _, key := range []string{"id", "name"} {
+		for _, f := range rec {
+			if f.name == key {
+				return tableValue(f.value)
+			}
+		}
+	}
+	return ""
+}
+
+func recordName(rec []field) string {
+	for _, key := range []string{"name", "title", "type"} {
+		for _, f := range rec {
+			if f.name == key {
+				if s, ok := f.value.(string); ok && s != "" {
+					return s
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func changedFields(old, cur []field) []string {
+	var changed []string
+	for _, f := range cur {
+		var prev interface{}
+		for _, of := range old {
+			if of.name == f.name {
+				prev = of.value
+				break
+			}
+		}
+		if !sameValue(prev, f.value) {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
+
+func sameValue(a, b interface{}) bool {
+	var ab, bb bytes.Buffer
+	aw, bw := bufio.NewWriter(&ab), bufio.NewWriter(&bb)
+	writeJSON(aw, a)
+	writeJSON(bw, b)
+	aw.Flush()
+	bw.Flush()
+	return bytes.Equal(ab.Bytes(), bb.Bytes())
+}
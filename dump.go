@@ -0,0 +1,440 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+// dumpSections maps section names to the World collections they dump.
+var dumpSections = []struct {
+	name    string
+	records func(w *lg.World) interface{}
+}{
+	{"regions", func(w *lg.World) interface{} { return w.Regions }},
+	{"undergroundregions", func(w *lg.World) interface{} { return w.UndergroundRegions }},
+	{"sites", func(w *lg.World) interface{} { return w.Sites }},
+	{"artifacts", func(w *lg.World) interface{} { return w.Artifacts }},
+	{"figures", func(w *lg.World) interface{} { return w.Figures }},
+	{"entities", func(w *lg.World) interface{} { return w.Entities }},
+	{"events", func(w *lg.World) interface{} { return w.Events }},
+	{"collections", func(w *lg.World) interface{} { return w.Collections }},
+	{"eras", func(w *lg.World) interface{} { return w.Eras }},
+	{"writtencontents", func(w *lg.World) interface{} { return w.WrittenContents }},
+	{"poeticforms", func(w *lg.World) interface{} { return w.PoeticForms }},
+	{"musicalforms", func(w *lg.World) interface{} { return w.MusicalForms }},
+	{"danceforms", func(w *lg.World) interface{} { return w.DanceForms }},
+}
+
+func dumpMain(args []string) {
+	fs := newFlagSet("dump")
+	sections := fs.String("sections", "", "comma separated sections to dump (default all)")
+	format := fs.String("format", "table", "output format: table, json, ndjson or yaml")
+	fields := fs.String("fields", "", "comma separated fields to include (default all; table defaults to simple fields)")
+	usage := fs.Usage
+	fs.Usage = func() {
+		usage()
+		names := make([]string, len(dumpSections))
+		for i, s := range dumpSections {
+			names[i] = s.name
+		}
+		fmt.Fprintf(os.Stderr, "\nsections: %s\n", strings.Join(names, ","))
+	}
+	parseFlags(fs, args, 1)
+
+	d := &dumper{format: *format}
+	if *fields != "" {
+		d.fields = strings.Split(*fields, ",")
+	}
+	switch d.format {
+	case "table", "json", "ndjson", "yaml":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", d.format)
+		os.Exit(exitUsage)
+	}
+	if *sections != "" {
+		for _, name := range strings.Split(*sections, ",") {
+			if !validSection(name) {
+				fmt.Fprintf(os.Stderr, "unknown section %q\n", name)
+				os.Exit(exitUsage)
+			}
+			d.sections = append(d.sections, name)
+		}
+	}
+
+	world := load(fs.Args())
+	if err := d.dump(os.Stdout, world); err != nil {
+		fmt.Fprintf(os.Stderr, "error dumping: %v\n", err)
+		os.Exit(exitIO)
+	}
+}
+
+func validSection(name string) bool {
+	for _, s := range dumpSections {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dumper streams World sections record by record.
+type dumper struct {
+	sections []string
+	format   string
+	fields   []string
+
+	// where filters records; matched counts the records dumped
+	where   []condition
+	matched int
+}
+
+// field is a named value in a dumped record. Values are scalars, []interface{}
+// or nested []field records.
+type field struct {
+	name  string
+	value interface{}
+}
+
+func (d *dumper) dump(out io.Writer, world *lg.World) error {
+	w := bufio.NewWriter(out)
+	first := true
+	if d.format == "json" {
+		w.WriteString("{")
+	}
+	for _, s := range dumpSections {
+		if len(d.sections) > 0 && !contains(d.sections, s.name) {
+			continue
+		}
+		records := reflect.ValueOf(s.records(world))
+		var err error
+		switch d.format {
+		case "table":
+			err = d.table(w, s.name, records)
+		case "json":
+			if !first {
+				w.WriteString(",")
+			}
+			fmt.Fprintf(w, "\n%q: [", s.name)
+			n := 0
+			err = d.each(records, func(rec []field) error {
+				if n++; n > 1 {
+					w.WriteString(",")
+				}
+				w.WriteString("\n  ")
+				return writeJSON(w, rec)
+			})
+			w.WriteString("\n]")
+		case "ndjson":
+			err = d.each(records, func(rec []field) error {
+				err := writeJSON(w, rec)
+				w.WriteString("\n")
+				return err
+			})
+		case "yaml":
+			fmt.Fprintf(w, "%s:\n", s.name)
+			err = d.each(records, func(rec []field) error {
+				writeYAML(w, rec, 1, true)
+				return nil
+			})
+		}
+		if err != nil {
+			return err
+		}
+		first = false
+	}
+	if d.format == "json" {
+		w.WriteString("\n}\n")
+	}
+	return w.Flush()
+}
+
+// each calls f with every record matching the dumper's conditions.
+func (d *dumper) each(records reflect.Value, f func([]field) error) error {
+	for i := 0; i < records.Len(); i++ {
+		rec := toRecord(records.Index(i))
+		if !d.match(rec) {
+			continue
+		}
+		d.matched++
+		if err := f(d.selectFields(rec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count returns the number of records matching the dumper's conditions.
+func (d *dumper) count(records reflect.Value) int {
+	n := 0
+	for i := 0; i < records.Len(); i++ {
+		if d.match(toRecord(records.Index(i))) {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *dumper) match(rec []field) bool {
+	for _, c := range d.where {
+		if !c.match(rec) {
+			return false
+		}
+	}
+	return true
+}
+
+// table writes records as aligned columns, flushing every few hundred rows
+// so a huge section isn't buffered in memory.
+func (d *dumper) table(w io.Writer, name string, records reflect.Value) error {
+	n := records.Len()
+	if len(d.where) > 0 {
+		n = d.count(records)
+	}
+	fmt.Fprintf(w, "%s (%d)\n", titleCase(name), n)
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	n = 0
+	err := d.each(records, func(rec []field) error {
+		if n%500 == 0 {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			var cols []string
+			for _, f := range rec {
+				if d.fields != nil || isScalar(f.value) {
+					cols = append(cols, f.name)
+				}
+			}
+			fmt.Fprintln(tw, strings.Join(cols, "\t"))
+		}
+		n++
+		var cols []string
+		for _, f := range rec {
+			if d.fields == nil && !isScalar(f.value) {
+				continue
+			}
+			cols = append(cols, tableValue(f.value))
+		}
+		_, err := fmt.Fprintln(tw, strings.Join(cols, "\t"))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+// selectFields returns only the fields selected by -fields.
+func (d *dumper) selectFields(rec []field) []field {
+	if d.fields == nil {
+		return rec
+	}
+	out := make([]field, 0, len(d.fields))
+	for _, name := range d.fields {
+		for _, f := range rec {
+			if f.name == name {
+				out = append(out, f)
+			}
+		}
+	}
+	return out
+}
+
+// toRecord converts a struct (pointer) into its fields named by their json
+// tags.
+func toRecord(v reflect.Value) []field {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	t := v.Type()
+	rec := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		rec = append(rec, field{name, toValue(v.Field(i))})
+	}
+	return rec
+}
+
+func toValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return toValue(v.Elem())
+	case reflect.Struct:
+		return toRecord(v)
+	case reflect.Slice:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = toValue(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		out := make([]field, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out = append(out, field{fmt.Sprint(iter.Key().Interface()), toValue(iter.Value())})
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case []field, []interface{}:
+		return false
+	}
+	return true
+}
+
+func tableValue(v interface{}) string {
+	switch v := v.(type) {
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = tableValue(e)
+		}
+		return strings.Join(parts, ",")
+	case []field:
+		parts := make([]string, len(v))
+		for i, f := range v {
+			parts[i] = f.name + "=" + tableValue(f.value)
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	case nil:
+		return "-"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeJSON(w *bufio.Writer, v interface{}) error {
+	switch v := v.(type) {
+	case []field:
+		w.WriteString("{")
+		for i, f := range v {
+			if i > 0 {
+				w.WriteString(",")
+			}
+			w.WriteString(strconv.Quote(f.name))
+			w.WriteString(":")
+			if err := writeJSON(w, f.value); err != nil {
+				return err
+			}
+		}
+		w.WriteString("}")
+	case []interface{}:
+		w.WriteString("[")
+		for i, e := range v {
+			if i > 0 {
+				w.WriteString(",")
+			}
+			if err := writeJSON(w, e); err != nil {
+				return err
+			}
+		}
+		w.WriteString("]")
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		w.Write(b)
+	}
+	return nil
+}
+
+// writeYAML writes v as a block style YAML value. item is true if v is a
+// list item and needs a "- " prefix.
+func writeYAML(w *bufio.Writer, v interface{}, depth int, item bool) {
+	indent := strings.Repeat("  ", depth)
+	switch v := v.(type) {
+	case []field:
+		if len(v) == 0 {
+			if item {
+				fmt.Fprintf(w, "%s- {}\n", indent)
+			}
+			return
+		}
+		for i, f := range v {
+			prefix := indent
+			if item && i == 0 {
+				prefix = indent + "- "
+			} else if item {
+				prefix = indent + "  "
+			}
+			switch fv := f.value.(type) {
+			case []field:
+				if len(fv) == 0 {
+					fmt.Fprintf(w, "%s%s: {}\n", prefix, f.name)
+					continue
+				}
+				fmt.Fprintf(w, "%s%s:\n", prefix, f.name)
+				writeYAML(w, fv, depth+2, false)
+			case []interface{}:
+				if len(fv) == 0 {
+					fmt.Fprintf(w, "%s%s: []\n", prefix, f.name)
+					continue
+				}
+				fmt.Fprintf(w, "%s%s:\n", prefix, f.name)
+				for _, e := range fv {
+					writeYAML(w, e, depth+2, true)
+				}
+			default:
+				fmt.Fprintf(w, "%s%s: %s\n", prefix, f.name, yamlScalar(fv))
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			writeYAML(w, e, depth, true)
+		}
+	default:
+		fmt.Fprintf(w, "%s- %s\n", indent, yamlScalar(v))
+	}
+}
+
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		// JSON strings are valid YAML
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
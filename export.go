@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+func exportMain(args []string) {
+	kind := ""
+	if len(args) > 0 {
+		kind, args = args[0], args[1:]
+	}
+	switch kind {
+	case "chronicle":
+		exportChronicle(args)
+	case "snapshot":
+		exportSnapshot(args)
+	case "-h", "-help", "--help":
+		newFlagSet("export").Usage()
+	default:
+		newFlagSet("export").Usage()
+		os.Exit(exitUsage)
+	}
+}
+
+// exportSnapshot writes a snapshot of the world which loads much faster than
+// legends xml.
+func exportSnapshot(args []string) {
+	fs := newFlagSet("export")
+	out := fs.String("o", "", "output file (required)")
+	parseFlags(fs, args, 1)
+	if *out == "" {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	world := load(fs.Args())
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to create file %q: %v\n", *out, err)
+		os.Exit(exitIO)
+	}
+	err = world.WriteSnapshot(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing snapshot: %v\n", err)
+		os.Exit(exitIO)
+	}
+}
+
+func exportChronicle(args []string) {
+	fs := newFlagSet("export")
+	format := fs.String("format", "markdown", "output format: markdown or html")
+	chapters := fs.String("chapters", "era", "one chapter per era or century")
+	civ := fs.Int("civ", -1, "only include the history of this entity id")
+	site := fs.Int("site", -1, "only include the history of this site id")
+	figures := fs.Int("figures", 20, "number of notable figures to write biographies for")
+	out := fs.String("o", "", "output file (default stdout)")
+	parseFlags(fs, args, 1)
+
+	var bw bookWriter
+	switch *format {
+	case "markdown", "md":
+		bw = &markdownWriter{}
+	case "html":
+		bw = &htmlWriter{}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(exitUsage)
+	}
+	if *chapters != "era" && *chapters != "century" {
+		fmt.Fprintf(os.Stderr, "unknown chapters %q\n", *chapters)
+		os.Exit(exitUsage)
+	}
+
+	world := load(fs.Args())
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to create file %q: %v\n", *out, err)
+			os.Exit(exitIO)
+		}
+		defer f.Close()
+		w = f
+	}
+	buf := bufio.NewWriter(w)
+	c := newChronicle(world, *civ, *site)
+	c.byCentury = *chapters == "century"
+	c.figures = *figures
+	err := c.write(bw, buf)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing chronicle: %v\n", err)
+		os.Exit(exitIO)
+	}
+}
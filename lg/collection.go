@@ -0,0 +1,78 @@
+package lg
+
+import "encoding/xml"
+
+// Collection is a historical event collection: a group of events such as a
+// war, battle, duel or site conquest.
+type Collection struct {
+	ID        int `xml:"id" json:"id"`
+	StartYear int `xml:"start_year" json:"start_year"`
+	EndYear   int `xml:"end_year" json:"end_year"`
+
+	// Type values: war,battle,duel,site conquered,beast attack,abduction,
+	// theft,purge,journey,occasion,...
+	Type string `xml:"type" json:"type"`
+	Name string `xml:"name" json:"name,omitempty"`
+
+	EventIDs      []int `xml:"event" json:"event"`
+	CollectionIDs []int `xml:"eventcol" json:"eventcol,omitempty"`
+
+	// WarID is set when Type=battle,site conquered
+	WarID int `xml:"war_eventcol" json:"war_eventcol"`
+
+	// AggressorEntityID and DefenderEntityID are set when Type=war
+	AggressorEntityID int `xml:"aggressor_ent_id" json:"aggressor_ent_id"`
+	DefenderEntityID  int `xml:"defender_ent_id" json:"defender_ent_id"`
+
+	SiteID int `xml:"site_id" json:"site_id"`
+
+	// AttackingFigureIDs, DefendingFigureIDs and Outcome are set when
+	// Type=battle
+	AttackingFigureIDs []int  `xml:"attacking_hfid" json:"attacking_hfid,omitempty"`
+	DefendingFigureIDs []int  `xml:"defending_hfid" json:"defending_hfid,omitempty"`
+	Outcome            string `xml:"outcome" json:"outcome,omitempty"`
+}
+
+func (c *Collection) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
+}
+
+func (c *Collection) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type collection Collection
+	v := collection{WarID: -1, AggressorEntityID: -1, DefenderEntityID: -1, SiteID: -1}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*c = Collection(v)
+	return nil
+}
+
+func (w *World) Collection(id int) *Collection {
+	return w.colidx[id]
+}
+
+// CollectionEvents returns the events in a collection.
+func (w *World) CollectionEvents(c *Collection) []*Event {
+	events := make([]*Event, 0, len(c.EventIDs))
+	for _, id := range c.EventIDs {
+		if e := w.Event(id); e != nil {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// SubCollections returns the collections in a collection such as the battles
+// of a war.
+func (w *World) SubCollections(c *Collection) []*Collection {
+	cols := make([]*Collection, 0, len(c.CollectionIDs))
+	for _, id := range c.CollectionIDs {
+		if sc := w.Collection(id); sc != nil {
+			cols = append(cols, sc)
+		}
+	}
+	return cols
+}
@@ -0,0 +1,118 @@
+package lg
+
+type Era struct {
+	Name      string `xml:"name" json:"name"`
+	StartYear int    `xml:"start_year" json:"start_year"`
+
+	// EndYear is the last year of the era or -1 for the current era.
+	EndYear int `xml:"-" json:"end_year"`
+}
+
+// String returns the era's name or "" for a nil era.
+func (e *Era) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.Name
+}
+
+// EraEvents are the events which happened during an Era.
+type EraEvents struct {
+	Era    *Era
+	Events []*Event
+}
+
+// EraStats summarizes what happened during an Era.
+type EraStats struct {
+	Era            *Era
+	Events         int
+	Births         int
+	Deaths         int
+	SitesFounded   int
+	SitesDestroyed int
+	EventTypes     map[string]int
+}
+
+// Era returns the era a year falls in or nil if the world has no eras.
+// Years before the first era belong to the first era.
+func (w *World) Era(year int) *Era {
+	if len(w.Eras) == 0 {
+		return nil
+	}
+	era := w.Eras[0]
+	for _, e := range w.Eras[1:] {
+		if e.StartYear > year {
+			break
+		}
+		era = e
+	}
+	return era
+}
+
+// ByEra groups events by the era they happened in. Events must be in
+// chronological order.
+func (w *World) ByEra(events []*Event) []*EraEvents {
+	var out []*EraEvents
+	var cur *EraEvents
+	for _, e := range events {
+		era := w.Era(e.Year)
+		if cur == nil || cur.Era != era {
+			cur = &EraEvents{Era: era}
+			out = append(out, cur)
+		}
+		cur.Events = append(cur.Events, e)
+	}
+	return out
+}
+
+// EraStats returns statistics for each era. If the world has no eras a
+// single EraStats with a nil Era covers all of history.
+func (w *World) EraStats() []*EraStats {
+	stats := make([]*EraStats, 0, len(w.Eras))
+	byEra := map[*Era]*EraStats{}
+	get := func(year int) *EraStats {
+		era := w.Era(year)
+		s := byEra[era]
+		if s == nil {
+			s = &EraStats{Era: era, EventTypes: map[string]int{}}
+			byEra[era] = s
+		}
+		return s
+	}
+	for _, era := range w.Eras {
+		stats = append(stats, get(era.StartYear))
+	}
+	if len(stats) == 0 {
+		stats = append(stats, get(0))
+	}
+
+	for _, e := range w.Events {
+		s := get(e.Year)
+		s.Events++
+		s.EventTypes[e.Type]++
+		switch e.Type {
+		case "created site":
+			s.SitesFounded++
+		case "destroyed site":
+			s.SitesDestroyed++
+		}
+	}
+	for _, f := range w.Figures {
+		if f.BirthYear != -1 {
+			get(f.BirthYear).Births++
+		}
+		if f.DiedIn != -1 {
+			get(f.DiedIn).Deaths++
+		}
+	}
+	return stats
+}
+
+func (w *World) initEras() {
+	for i, e := range w.Eras {
+		e.EndYear = -1
+		if i+1 < len(w.Eras) {
+			e.EndYear = w.Eras[i+1].StartYear - 1
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package lg
+
+import (
+	"encoding/xml"
+	"slices"
+	"sort"
+)
+
+func (e *Event) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	// DF omits fields which don't apply to an event's type, so default IDs to
+	// -1 like DF does instead of 0 which is a valid ID.
+	type event Event
+	v := event{
+		Seconds:        -1,
+		AttackerCivID:  -1,
+		DefenderCivID:  -1,
+		CivID:          -1,
+		NewSiteCivID:   -1,
+		FigureID:       -1,
+		SlayerFigureID: -1,
+		SlayerItemID:   -1,
+		HistFigureID:   -1,
+		DoerFigureID:   -1,
+		TargetFigureID: -1,
+		ArtifactID:     -1,
+		SiteCivID:      -1,
+		SiteID:         -1,
+		SubregionID:    -1,
+		FeatureLayerID: -1,
+
+		SnatcherFigureID:  -1,
+		WoundeeFigureID:   -1,
+		WounderFigureID:   -1,
+		Group1FigureID:    -1,
+		Group2FigureID:    -1,
+		HFTargetID:        -1,
+		AttackerFigureID:  -1,
+		DefenderFigureID:  -1,
+		GroupFigureID:     -1,
+		TricksterFigureID: -1,
+		TargetEntityID:    -1,
+		EntityID:          -1,
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*e = Event(v)
+	return nil
+}
+
+// ticksPerMonth is the length of a month in seconds72, DF's ticks. A year has
+// twelve months and four seasons of three months starting with spring.
+const ticksPerMonth = 28 * 1200
+
+var seasons = []string{"spring", "summer", "autumn", "winter"}
+
+// Season returns when in the year e happened, e.g. "early spring", or "" if
+// the export doesn't say.
+func (e *Event) Season() string {
+	if e.Seconds < 0 {
+		return ""
+	}
+	month := e.Seconds / ticksPerMonth % 12
+	return []string{"early", "mid", "late"}[month%3] + " " + seasons[month/3]
+}
+
+// Participant is a figure or entity involved in an event and its role in it.
+type Participant struct {
+	// Kind is "figure", "entity" or "artifact"
+	Kind string
+	Role string
+	ID   int
+}
+
+// Participants returns the figures and entities involved in e.
+func (e *Event) Participants() []Participant {
+	var out []Participant
+	add := func(kind, role string, id int) {
+		if id != -1 {
+			out = append(out, Participant{kind, role, id})
+		}
+	}
+	add("figure", "figure", e.FigureID)
+	add("figure", "slayer", e.SlayerFigureID)
+	add("figure", "figure", e.HistFigureID)
+	add("figure", "doer", e.DoerFigureID)
+	add("figure", "target", e.TargetFigureID)
+	add("figure", "snatcher", e.SnatcherFigureID)
+	add("figure", "wounded", e.WoundeeFigureID)
+	add("figure", "wounder", e.WounderFigureID)
+	add("figure", "group 1", e.Group1FigureID)
+	add("figure", "group 2", e.Group2FigureID)
+	add("figure", "target", e.HFTargetID)
+	add("figure", "attacker", e.AttackerFigureID)
+	add("figure", "defender", e.DefenderFigureID)
+	add("figure", "group", e.GroupFigureID)
+	add("figure", "trickster", e.TricksterFigureID)
+	add("entity", "attacker", e.AttackerCivID)
+	add("entity", "defender", e.DefenderCivID)
+	add("entity", "civ", e.CivID)
+	add("entity", "new owner", e.NewSiteCivID)
+	add("entity", "site civ", e.SiteCivID)
+	add("entity", "target", e.TargetEntityID)
+	add("entity", "entity", e.EntityID)
+	add("artifact", "artifact", e.ArtifactID)
+	return out
+}
+
+// FigureIDs returns the IDs of the figures involved in e.
+func (e *Event) FigureIDs() []int {
+	return ids(e.FigureID, e.SlayerFigureID, e.HistFigureID, e.DoerFigureID, e.TargetFigureID,
+		e.SnatcherFigureID, e.WoundeeFigureID, e.WounderFigureID, e.Group1FigureID, e.Group2FigureID,
+		e.HFTargetID, e.AttackerFigureID, e.DefenderFigureID, e.GroupFigureID, e.TricksterFigureID)
+}
+
+// EntityIDs returns the IDs of the entities involved in e.
+func (e *Event) EntityIDs() []int {
+	return ids(e.AttackerCivID, e.DefenderCivID, e.CivID, e.NewSiteCivID, e.SiteCivID, e.TargetEntityID, e.EntityID)
+}
+
+// ids returns the set IDs (!= -1) without duplicates
+func ids(all ...int) []int {
+	out := all[:0]
+outer:
+	for _, id := range all {
+		if id == -1 {
+			continue
+		}
+		for _, o := range out {
+			if o == id {
+				continue outer
+			}
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+func (w *World) Event(id int) *Event {
+	return w.evidx[id]
+}
+
+// initEvents indexes the events by the figures, sites, entities and
+// artifacts involved in them and by year.
+func (w *World) initEvents() {
+	w.figevidx = map[int][]*Event{}
+	w.siteevidx = map[int][]*Event{}
+	w.entevidx = map[int][]*Event{}
+	w.artevidx = map[int][]*Event{}
+	w.yearevidx = slices.Clone(w.Events)
+	sort.SliceStable(w.yearevidx, func(i, j int) bool { return w.yearevidx[i].Year < w.yearevidx[j].Year })
+	for _, e := range w.yearevidx {
+		for _, id := range e.FigureIDs() {
+			w.figevidx[id] = append(w.figevidx[id], e)
+		}
+		for _, id := range e.EntityIDs() {
+			w.entevidx[id] = append(w.entevidx[id], e)
+		}
+		if e.SiteID != -1 {
+			w.siteevidx[e.SiteID] = append(w.siteevidx[e.SiteID], e)
+		}
+		if e.ArtifactID != -1 {
+			w.artevidx[e.ArtifactID] = append(w.artevidx[e.ArtifactID], e)
+		}
+	}
+}
+
+// EventsForFigure, EventsForSite, EventsForEntity and EventsForArtifact
+// return the events a record is involved in in chronological order. The
+// slices are shared so callers must not modify their elements.
+func (w *World) EventsForFigure(id int) []*Event { return slices.Clip(w.figevidx[id]) }
+
+func (w *World) EventsForSite(id int) []*Event { return slices.Clip(w.siteevidx[id]) }
+
+func (w *World) EventsForEntity(id int) []*Event { return slices.Clip(w.entevidx[id]) }
+
+func (w *World) EventsForArtifact(id int) []*Event { return slices.Clip(w.artevidx[id]) }
+
+// EventsInYears returns the events from year from to year to inclusive in
+// chronological order. The slice is shared so callers must not modify its
+// elements.
+func (w *World) EventsInYears(from, to int) []*Event {
+	i := sort.Search(len(w.yearevidx), func(i int) bool { return w.yearevidx[i].Year >= from })
+	j := sort.Search(len(w.yearevidx), func(i int) bool { return w.yearevidx[i].Year > to })
+	if i >= j {
+		return nil
+	}
+	return slices.Clip(w.yearevidx[i:j])
+}
@@ -0,0 +1,168 @@
+package lg
+
+import (
+	"encoding/xml"
+	"slices"
+	"strings"
+)
+
+// Flag is true if an empty element like <deity/> is present.
+type Flag bool
+
+func (f *Flag) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*f = true
+	return d.Skip()
+}
+
+// FigureTags are the tags classifying supernatural figures in the order
+// they're listed.
+var FigureTags = []string{
+	"deity", "force", "megabeast", "titan", "forgotten beast", "vampire",
+	"werebeast", "necromancer", "demon", "ghost",
+}
+
+// megabeasts are the races of megabeasts besides titans and forgotten beasts.
+var megabeasts = []string{"dragon", "hydra", "roc", "bronze colossus"}
+
+// Is returns whether f is tagged tag.
+func (f *Figure) Is(tag string) bool { return slices.Contains(f.Tags, tag) }
+
+// Year returns the world's current year: the last year anything happened in.
+func (w *World) Year() int { return w.year }
+
+// initFigures derives the current year, figures' tags and whether they are
+// alive, their age, death and residence from events.
+func (w *World) initFigures() {
+	w.year = 0
+	for _, e := range w.Events {
+		w.year = max(w.year, e.Year)
+	}
+	for _, f := range w.Figures {
+		w.year = max(w.year, f.Appeared, f.BirthYear, f.DeathYear)
+		f.Alive = f.DeathYear == -1
+		f.DiedIn = f.DeathYear
+		f.DeathCause = ""
+		f.KillerID, f.DeathSiteID, f.ResidenceID = -1, -1, -1
+	}
+
+	for _, e := range w.Events {
+		f := w.Figure(e.FigureID)
+		if f == nil {
+			continue
+		}
+		switch e.Type {
+		case "hf died":
+			f.Alive = false
+			if f.DiedIn == -1 {
+				f.DiedIn = e.Year
+			}
+			f.DeathCause = e.Cause
+			f.KillerID = e.SlayerFigureID
+			f.DeathSiteID = e.SiteID
+		case "change hf state":
+			// Like initSites any other state ends the residence
+			f.ResidenceID = -1
+			if e.State == "settled" {
+				f.ResidenceID = e.SiteID
+			}
+		}
+	}
+
+	cursed := map[int][]string{}
+	worshipped := map[int]bool{}
+	for _, e := range w.Events {
+		if e.Type == "hf does interaction" && e.Interaction != "" {
+			cursed[e.TargetFigureID] = append(cursed[e.TargetFigureID], e.Interaction)
+		}
+	}
+	for _, ent := range w.Entities {
+		for _, id := range ent.WorshipIDs {
+			worshipped[id] = true
+		}
+	}
+
+	for _, f := range w.Figures {
+		f.Tags = classify(f, worshipped[f.ID], cursed[f.ID])
+		f.Age = -1
+		switch {
+		case f.BirthYear == -1:
+		case f.Alive:
+			f.Age = w.year - f.BirthYear
+		case f.DiedIn != -1:
+			f.Age = f.DiedIn - f.BirthYear
+		}
+	}
+}
+
+// classify returns the tags of f from its flags, race and interactions:
+// worshipped if an entity worships it and the interactions events cursed it
+// with.
+func classify(f *Figure, worshipped bool, curses []string) []string {
+	race := strings.ToLower(strings.ReplaceAll(f.Race, "_", " "))
+	interactions := strings.ToUpper(strings.Join(append(slices.Clone(f.ActiveInteractions), curses...), " "))
+	is := map[string]bool{
+		"deity":           bool(f.Deity) || worshipped && !bool(f.Force),
+		"force":           bool(f.Force),
+		"megabeast":       slices.Contains(megabeasts, race),
+		"titan":           strings.HasPrefix(race, "titan"),
+		"forgotten beast": strings.HasPrefix(race, "forgotten beast"),
+		"vampire":         strings.Contains(interactions, "VAMPIRE"),
+		"werebeast":       strings.Contains(interactions, "WEREBEAST"),
+		"necromancer":     strings.Contains(interactions, "SECRET"),
+		"demon":           strings.HasPrefix(race, "demon"),
+		"ghost":           bool(f.Ghost),
+	}
+	var tags []string
+	for _, t := range FigureTags {
+		if is[t] {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// Pantheon is the deities an entity worships.
+type Pantheon struct {
+	Entity  *Entity
+	Deities []*Figure
+}
+
+// Pantheons returns the pantheons of the entities worshipping deities and
+// forces, civilizations first, and a Pantheon with a nil Entity of the
+// deities no entity worships.
+func (w *World) Pantheons() []*Pantheon {
+	var out []*Pantheon
+	worshipped := map[int]bool{}
+	for _, e := range w.Entities {
+		p := &Pantheon{Entity: e}
+		for _, id := range e.WorshipIDs {
+			if f := w.Figure(id); f != nil {
+				p.Deities = append(p.Deities, f)
+				worshipped[id] = true
+			}
+		}
+		if len(p.Deities) > 0 {
+			out = append(out, p)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b *Pantheon) int {
+		ca, cb := a.Entity.Type == "civilization", b.Entity.Type == "civilization"
+		switch {
+		case ca && !cb:
+			return -1
+		case cb && !ca:
+			return 1
+		}
+		return 0
+	})
+	rest := &Pantheon{}
+	for _, f := range w.Figures {
+		if (f.Is("deity") || f.Is("force")) && !worshipped[f.ID] {
+			rest.Deities = append(rest.Deities, f)
+		}
+	}
+	if len(rest.Deities) > 0 {
+		out = append(out, rest)
+	}
+	return out
+}
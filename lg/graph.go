@@ -0,0 +1,193 @@
+package lg
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NodeKinds are the kinds of records in the World's graph.
+var NodeKinds = []string{"figure", "entity", "site", "artifact", "event"}
+
+// Node is a record in the World's graph: figures, entities, sites, artifacts
+// and events linked by the links of the export and by taking part in events.
+type Node struct {
+	Kind string
+	ID   int
+}
+
+// ParseNode parses a node written like "figure:12".
+func ParseNode(s string) (Node, error) {
+	kind, id, ok := strings.Cut(s, ":")
+	n, err := strconv.Atoi(id)
+	if !ok || err != nil || !slices.Contains(NodeKinds, kind) {
+		return Node{}, fmt.Errorf("bad node %q: want kind:id where kind is one of %s", s, strings.Join(NodeKinds, ", "))
+	}
+	return Node{kind, n}, nil
+}
+
+func (n Node) String() string { return fmt.Sprintf("%s:%d", n.Kind, n.ID) }
+
+func (n Node) MarshalText() ([]byte, error) { return []byte(n.String()), nil }
+
+// Edge links two nodes. Type is the link type, the relationship or the role
+// of a record in an event.
+type Edge struct {
+	From Node   `json:"from"`
+	To   Node   `json:"to"`
+	Type string `json:"type"`
+}
+
+// HasNode returns whether the record n exists.
+func (w *World) HasNode(n Node) bool {
+	switch n.Kind {
+	case "figure":
+		return w.Figure(n.ID) != nil
+	case "entity":
+		return w.Entity(n.ID) != nil
+	case "site":
+		return w.Site(n.ID) != nil
+	case "artifact":
+		return w.Artifact(n.ID) != nil
+	case "event":
+		return w.Event(n.ID) != nil
+	}
+	return false
+}
+
+// NodeName returns the name of the record n, or a description if it's an
+// event.
+func (w *World) NodeName(n Node) string {
+	switch n.Kind {
+	case "figure":
+		return w.figureName(n.ID)
+	case "entity":
+		return w.entityName(n.ID)
+	case "site":
+		return w.siteName(n.ID)
+	case "artifact":
+		if a := w.Artifact(n.ID); a != nil {
+			return a.Name
+		}
+	case "event":
+		if e := w.Event(n.ID); e != nil {
+			return w.RenderEvent(e)
+		}
+	}
+	return "unknown " + n.String()
+}
+
+// initGraph indexes the links between records in both directions: figures'
+// entity and site links, site owners and the deities entities worship.
+// Relationships and events have their own indexes.
+func (w *World) initGraph() {
+	w.linkidx = map[Node][]Edge{}
+	link := func(a, b Node, typ string) {
+		w.linkidx[a] = append(w.linkidx[a], Edge{a, b, typ})
+		w.linkidx[b] = append(w.linkidx[b], Edge{b, a, typ})
+	}
+	for _, f := range w.Figures {
+		for _, l := range f.Entities {
+			link(Node{"figure", f.ID}, Node{"entity", l.ID}, l.Type)
+		}
+		for _, l := range f.Sites {
+			link(Node{"figure", f.ID}, Node{"site", l.ID}, l.Type)
+		}
+	}
+	for _, e := range w.Entities {
+		for _, id := range e.WorshipIDs {
+			link(Node{"entity", e.ID}, Node{"figure", id}, "worship")
+		}
+	}
+	for _, s := range w.Sites {
+		owners := map[int]bool{}
+		for _, o := range s.Owners {
+			owners[o.CivID], owners[o.SiteCivID] = true, true
+		}
+		delete(owners, -1)
+		for id := range owners {
+			link(Node{"site", s.ID}, Node{"entity", id}, "owner")
+		}
+	}
+}
+
+// edges returns the edges from n.
+func (w *World) edges(n Node) []Edge {
+	out := slices.Clone(w.linkidx[n])
+	var events []*Event
+	switch n.Kind {
+	case "figure":
+		for _, r := range w.relidx[n.ID] {
+			out = append(out, Edge{n, Node{"figure", r.Other(n.ID)}, r.Type})
+		}
+		events = w.figevidx[n.ID]
+	case "entity":
+		events = w.entevidx[n.ID]
+	case "site":
+		events = w.siteevidx[n.ID]
+	case "artifact":
+		events = w.artevidx[n.ID]
+	case "event":
+		e := w.Event(n.ID)
+		if e == nil {
+			return out
+		}
+		for _, p := range e.Participants() {
+			out = append(out, Edge{n, Node{p.Kind, p.ID}, p.Role})
+		}
+		if e.SiteID != -1 {
+			out = append(out, Edge{n, Node{"site", e.SiteID}, "site"})
+		}
+		return out
+	}
+	for _, e := range events {
+		out = append(out, Edge{n, Node{"event", e.ID}, role(e, n)})
+	}
+	return out
+}
+
+// role returns the role of n in e.
+func role(e *Event, n Node) string {
+	if n.Kind == "site" {
+		return "site"
+	}
+	for _, p := range e.Participants() {
+		if p.Kind == n.Kind && p.ID == n.ID {
+			return p.Role
+		}
+	}
+	return n.Kind
+}
+
+// Connect returns the shortest chain of edges from one record to another, nil
+// if they aren't connected or the same record. Hops through an event node
+// are explained by the event.
+func (w *World) Connect(from, to Node) []Edge {
+	if from == to || !w.HasNode(from) || !w.HasNode(to) {
+		return nil
+	}
+	// via is the edge each node was first reached by
+	via := map[Node]Edge{from: {}}
+	queue := []Node{from}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range w.edges(n) {
+			if _, seen := via[e.To]; seen || !w.HasNode(e.To) {
+				continue
+			}
+			via[e.To] = e
+			if e.To == to {
+				var path []Edge
+				for n := to; n != from; n = via[n].From {
+					path = append(path, via[n])
+				}
+				slices.Reverse(path)
+				return path
+			}
+			queue = append(queue, e.To)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package lg
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Leader is a holder of a civilization's position listed in
+// world_history.txt.
+type Leader struct {
+	Position   string `json:"position"`
+	Name       string `json:"name"`
+	FigureID   int    `json:"hfid"`
+	BirthYear  int    `json:"birth_year"`
+	DeathYear  int    `json:"death_year"`
+	ReignStart int    `json:"reign_start"`
+}
+
+var (
+	leaderYears = regexp.MustCompile(`b\.\s*([-?\d]+)\s*d\.\s*([-?\d]+)(?:,\s*Reign Begin:\s*([-?\d]+))?`)
+)
+
+// MergeHistory parses the world_history.txt DF exports from legends mode and
+// adds the leaders of each civilization to its Entity.
+func (w *World) MergeHistory(r io.Reader) error {
+	entities := map[string]*Entity{}
+	for _, e := range w.Entities {
+		if e.Name != "" {
+			entities[strings.ToLower(e.Name)] = e
+		}
+	}
+	figures := figuresByName(w.Figures)
+
+	var civ *Entity
+	position := ""
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), " \r")
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case line == "":
+			continue
+		case indent == 0:
+			// Civilization headers are: Name, "Translation", Race
+			civ, position = nil, ""
+			if i := strings.Index(line, ", \""); i != -1 {
+				civ = entities[strings.ToLower(line[:i])]
+				if civ != nil {
+					civ.Leaders = civ.Leaders[:0]
+				}
+			}
+		case civ == nil:
+		case indent == 1:
+			position = ""
+			if strings.HasSuffix(line, " List") && line != " Worship List" {
+				position = strings.TrimSuffix(strings.TrimSpace(line), " List")
+			}
+		case indent == 2 && position != "":
+			civ.Leaders = append(civ.Leaders, parseLeader(position, strings.TrimSpace(line), figures))
+		}
+	}
+	return s.Err()
+}
+
+// parseLeader parses a leader line like:
+//
+//	[*] Urist Kolbomrel (b.12 d. 140, Reign Begin: 87), *** Original Line
+func parseLeader(position, line string, figures map[string]*Figure) *Leader {
+	l := &Leader{Position: position, FigureID: -1, BirthYear: -1, DeathYear: -1, ReignStart: -1}
+	line = strings.TrimSpace(strings.TrimPrefix(line, "[*]"))
+	l.Name = line
+	if i := strings.Index(line, " ("); i != -1 {
+		l.Name = line[:i]
+	}
+	if m := leaderYears.FindStringSubmatch(line); m != nil {
+		l.BirthYear, l.DeathYear, l.ReignStart = historyYear(m[1]), historyYear(m[2]), historyYear(m[3])
+	}
+	if f := figures[strings.ToLower(l.Name)]; f != nil {
+		l.FigureID = f.ID
+	}
+	return l
+}
+
+// historyYear parses a year or returns -1 for unknown years such as "???"
+// and "---".
+func historyYear(s string) int {
+	y, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return y
+}
+
+func figuresByName(figures []*Figure) map[string]*Figure {
+	m := make(map[string]*Figure, len(figures))
+	for _, f := range figures {
+		m[strings.ToLower(f.Name)] = f
+	}
+	return m
+}
@@ -0,0 +1,94 @@
+package lg
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testWorld decodes and indexes a legends.xml document.
+func testWorld(t *testing.T, doc string) *World {
+	t.Helper()
+	w, err := New(xml.NewDecoder(strings.NewReader(doc)))
+	if err != nil {
+		t.Fatalf("decoding world: %v", err)
+	}
+	return w
+}
+
+const historyWorld = `<df_world>
+<historical_figures>
+<historical_figure><id>7</id><name>urist mcminer</name></historical_figure>
+</historical_figures>
+<entities>
+<entity><id>1</id><name>the realm of copper</name></entity>
+<entity><id>2</id><name>the goblin hordes</name></entity>
+</entities>
+</df_world>`
+
+func TestMergeHistory(t *testing.T) {
+	leader := func(position, name string, hfid, birth, death, reign int) *Leader {
+		return &Leader{Position: position, Name: name, FigureID: hfid, BirthYear: birth, DeathYear: death, ReignStart: reign}
+	}
+	cases := []struct {
+		name    string
+		history string
+		leaders map[int][]*Leader
+	}{
+		{
+			name: "leaders",
+			history: `Dostngoshun, "The Realm of Legends"
+
+The Realm Of Copper, "The Copper Realm", Dwarves
+ Worship List
+  Armok, deity: blood, war
+ king List
+  [*] Urist McMiner (b.-20 d. 50, Reign Begin: 1), *** Original Line, Never Married
+  [*] Zon Dragonslayer (b.10 d. ---, Reign Begin: 50), *** Elected
+      Child: Someone
+ general List
+  [*] Kib The Pale (b.??? d. ---, Reign Begin: ???)
+`,
+			leaders: map[int][]*Leader{1: {
+				leader("king", "Urist McMiner", 7, -20, 50, 1),
+				leader("king", "Zon Dragonslayer", -1, 10, -1, 50),
+				leader("general", "Kib The Pale", -1, -1, -1, -1),
+			}},
+		},
+		{
+			name: "unknown civilization",
+			history: `The Lost Realm, "The Lost", Elves
+ queen List
+  [*] Nobody (b.1 d. 2, Reign Begin: 1)
+The Goblin Hordes, "The Hordes", Goblins
+ Worship List
+  Nobody
+`,
+			leaders: map[int][]*Leader{},
+		},
+		{
+			name:    "crlf and missing years",
+			history: "The Goblin Hordes, \"The Hordes\", Goblins\r\n master List\r\n  [*] Snodub\r\n",
+			leaders: map[int][]*Leader{2: {leader("master", "Snodub", -1, -1, -1, -1)}},
+		},
+		{
+			name:    "truncated",
+			history: "The Realm Of Copper, \"The Copper Realm\", Dwarves\n king List\n  [*] Urist McMiner (b.-20 d",
+			leaders: map[int][]*Leader{1: {leader("king", "Urist McMiner", 7, -1, -1, -1)}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := testWorld(t, historyWorld)
+			if err := w.MergeHistory(strings.NewReader(c.history)); err != nil {
+				t.Fatalf("MergeHistory: %v", err)
+			}
+			for _, e := range w.Entities {
+				if want := c.leaders[e.ID]; !reflect.DeepEqual(e.Leaders, want) && len(e.Leaders)+len(want) > 0 {
+					t.Errorf("entity %d leaders = %+v, want %+v", e.ID, e.Leaders, want)
+				}
+			}
+		})
+	}
+}
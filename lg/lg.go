@@ -21,20 +21,109 @@ func New(d Decoder) (*World, error) {
 }
 
 type World struct {
-	XMLName            xml.Name             `xml:"df_world" json:"-"`
+	XMLName xml.Name `xml:"df_world" json:"-"`
+
+	// Name and AltName are only set by legends_plus.xml
+	Name    string `xml:"name" json:"name,omitempty"`
+	AltName string `xml:"altname" json:"altname,omitempty"`
+
 	Regions            []*Region            `xml:"regions>region" json:"regions"`
 	UndergroundRegions []*UndergroundRegion `xml:"underground_regions>underground_region" json:"underground_regions"`
 	Sites              []*Site              `xml:"sites>site" json:"sites"`
 	siteidx            map[int]*Site
 	Artifacts          []*Artifact `xml:"artifacts>artifact" json:"artifacts"`
-	Figures            []*Figure   `xml:"historical_figures>historical_figure" json:"historical_figures"`
+	artidx             map[int]*Artifact
+	Figures            []*Figure `xml:"historical_figures>historical_figure" json:"historical_figures"`
 	figidx             map[int]*Figure
 
 	// useless?
 	EntityPopulations []*EntityPopulation `xml:"entity_populations>entity_population" json:"-"`
 
 	Entities []*Entity `xml:"entities>entity" json:"entities"`
-	Events   []*Event  `xml:"historical_events>historical_event" json:"historical_events"`
+	entidx   map[int]*Entity
+	Events   []*Event `xml:"historical_events>historical_event" json:"historical_events"`
+	evidx    map[int]*Event
+
+	// Events by the records involved in them and in chronological order.
+	figevidx  map[int][]*Event
+	siteevidx map[int][]*Event
+	entevidx  map[int][]*Event
+	artevidx  map[int][]*Event
+	yearevidx []*Event
+
+	Collections []*Collection `xml:"historical_event_collections>historical_event_collection" json:"historical_event_collections"`
+	colidx      map[int]*Collection
+
+	Eras []*Era `xml:"historical_eras>historical_era" json:"historical_eras"`
+
+	WrittenContents []*WrittenContent `xml:"written_contents>written_content" json:"written_contents"`
+	wcidx           map[int]*WrittenContent
+	authoridx       map[int][]*WrittenContent
+	refidx          map[Reference][]*WrittenContent
+	PoeticForms     []*Form `xml:"poetic_forms>poetic_form" json:"poetic_forms"`
+	MusicalForms    []*Form `xml:"musical_forms>musical_form" json:"musical_forms"`
+	DanceForms      []*Form `xml:"dance_forms>dance_form" json:"dance_forms"`
+
+	// EventRelationships is only set by legends_plus.xml
+	EventRelationships []*EventRelationship `xml:"historical_event_relationships>historical_event_relationship" json:"historical_event_relationships,omitempty"`
+	relidx             map[int][]*Relationship
+
+	// linkidx are the links between records besides relationships and events
+	linkidx map[Node][]Edge
+
+	// Populations is only set by world_sites_and_pops.txt
+	Populations []*Population `xml:"-" json:"populations,omitempty"`
+
+	year int
+}
+
+// Merge decodes a supplemental export (such as DFHack's legends_plus.xml)
+// and merges its records into w by ID.
+func (w *World) Merge(d Decoder) error {
+	p := &World{}
+	if err := d.Decode(p); err != nil {
+		return err
+	}
+	if p.Name != "" {
+		w.Name, w.AltName = p.Name, p.AltName
+	}
+	if len(w.Eras) == 0 {
+		w.Eras = p.Eras
+	}
+	for _, ps := range p.Sites {
+		for _, st := range ps.Structures {
+			st.ID = st.PlusID
+		}
+		s := w.Site(ps.ID)
+		if s == nil {
+			w.Sites = append(w.Sites, ps)
+			continue
+		}
+		s.merge(ps)
+	}
+	w.EventRelationships = append(w.EventRelationships, p.EventRelationships...)
+	for _, pe := range p.Entities {
+		e := w.Entity(pe.ID)
+		if e == nil {
+			w.Entities = append(w.Entities, pe)
+			continue
+		}
+		e.merge(pe)
+	}
+	w.initWrittenContents()
+	for _, pc := range p.WrittenContents {
+		c := w.WrittenContent(pc.ID)
+		if c == nil {
+			w.WrittenContents = append(w.WrittenContents, pc)
+			continue
+		}
+		c.merge(pc)
+	}
+	w.PoeticForms = mergeForms(w.PoeticForms, p.PoeticForms)
+	w.MusicalForms = mergeForms(w.MusicalForms, p.MusicalForms)
+	w.DanceForms = mergeForms(w.DanceForms, p.DanceForms)
+	w.init()
+	return nil
 }
 
 func (w *World) init() {
@@ -43,10 +132,42 @@ func (w *World) init() {
 		w.siteidx[s.ID] = s
 	}
 
+	w.artidx = make(map[int]*Artifact, len(w.Artifacts))
+	for _, a := range w.Artifacts {
+		w.artidx[a.ID] = a
+	}
+
 	w.figidx = make(map[int]*Figure, len(w.Figures))
 	for _, f := range w.Figures {
 		w.figidx[f.ID] = f
 	}
+
+	w.entidx = make(map[int]*Entity, len(w.Entities))
+	for _, e := range w.Entities {
+		w.entidx[e.ID] = e
+	}
+
+	w.evidx = make(map[int]*Event, len(w.Events))
+	for _, e := range w.Events {
+		w.evidx[e.ID] = e
+	}
+	w.initEvents()
+
+	w.colidx = make(map[int]*Collection, len(w.Collections))
+	for _, c := range w.Collections {
+		w.colidx[c.ID] = c
+	}
+
+	w.initEras()
+	w.initSites()
+	w.initFigures()
+	w.initRelationships()
+	w.initGraph()
+	w.initWrittenContents()
+}
+
+func (w *World) Artifact(id int) *Artifact {
+	return w.artidx[id]
 }
 
 func (w *World) Figure(id int) *Figure {
@@ -57,40 +178,126 @@ func (w *World) Site(id int) *Site {
 	return w.siteidx[id]
 }
 
-func (w *World) FigureEvents(id int) <-chan *Event {
-	out := make(chan *Event, 100)
-	go func() {
-		defer close(out)
-		for _, e := range w.Events {
-			if e.FigureID == id || e.SlayerFigureID == id {
-				out <- e
-			}
+func (w *World) Entity(id int) *Entity {
+	return w.entidx[id]
+}
+
+// Region returns the region with the ID or nil.
+func (w *World) Region(id int) *Region {
+	for _, r := range w.Regions {
+		if r.ID == id {
+			return r
 		}
-	}()
-	return out
+	}
+	return nil
 }
 
 func (w *World) RenderEvent(e *Event) string {
 	switch e.Type {
+	case "created site":
+		return fmt.Sprintf("%s founded by %s", w.siteName(e.SiteID), w.entityName(e.SiteCivID))
 	case "destroyed site":
-		return fmt.Sprintf("Site %d for Civ %d destroyed by Civ %d", e.SiteID, e.DefenderCivID, e.AttackerCivID)
+		return fmt.Sprintf("%s of %s destroyed by %s", w.siteName(e.SiteID), w.entityName(e.DefenderCivID), w.entityName(e.AttackerCivID))
+	case "site taken over":
+		return fmt.Sprintf("%s taken over from %s by %s", w.siteName(e.SiteID), w.entityName(e.DefenderCivID), w.entityName(e.AttackerCivID))
+	case "reclaim site":
+		return fmt.Sprintf("%s reclaimed by %s", w.siteName(e.SiteID), w.entityName(e.CivID))
 	case "change hf state":
 		if e.SiteID != -1 {
-			return fmt.Sprintf("%s %s %s", w.Figure(e.FigureID), e.State, w.Site(e.SiteID))
+			return fmt.Sprintf("%s %s %s", w.figureName(e.FigureID), e.State, w.siteName(e.SiteID))
+		}
+		return fmt.Sprintf("%s %s", w.figureName(e.FigureID), e.State)
+	case "hf abducted":
+		return fmt.Sprintf("%s abducted by %s", w.figureName(e.TargetFigureID), w.figureName(e.SnatcherFigureID))
+	case "hf wounded":
+		return fmt.Sprintf("%s wounded by %s", w.figureName(e.WoundeeFigureID), w.figureName(e.WounderFigureID))
+	case "hf simple battle event":
+		return fmt.Sprintf("%s fought %s", w.figureName(e.Group1FigureID), w.figureName(e.Group2FigureID))
+	case "assume identity":
+		return fmt.Sprintf("%s fooled %s", w.figureName(e.TricksterFigureID), w.entityName(e.TargetEntityID))
+	case "entity created":
+		if e.SiteID != -1 {
+			return fmt.Sprintf("%s formed in %s", w.entityName(e.EntityID), w.siteName(e.SiteID))
 		}
-		return fmt.Sprintf("%s %s", w.Figure(e.FigureID), e.State)
+		return fmt.Sprintf("%s formed", w.entityName(e.EntityID))
 	case "hf died":
-		if slayer := w.Figure(e.SlayerFigureID); slayer != nil {
-			return fmt.Sprintf("%s slayed by %s", w.Figure(e.FigureID), slayer)
+		if e.SlayerFigureID != -1 {
+			return fmt.Sprintf("%s slayed by %s", w.figureName(e.FigureID), w.figureName(e.SlayerFigureID))
 		}
-		return fmt.Sprintf("%s died", w.Figure(e.FigureID))
+		return fmt.Sprintf("%s died", w.figureName(e.FigureID))
 	default:
-		return fmt.Sprint("Event %d in %d (unknown type %q)", e.ID, e.Year, e.Type)
+		return w.describeEvent(e)
 	}
 }
 
+// describeEvent describes an event of a type RenderEvent doesn't know from
+// its participants, e.g. "hf abducted: urist, the goblin hordes at boltwheels".
+func (w *World) describeEvent(e *Event) string {
+	var names []string
+	for _, p := range e.Participants() {
+		switch p.Kind {
+		case "figure":
+			names = append(names, w.figureName(p.ID))
+		case "entity":
+			names = append(names, w.entityName(p.ID))
+		case "artifact":
+			if a := w.Artifact(p.ID); a != nil {
+				names = append(names, a.Name)
+			}
+		}
+	}
+	s := e.Type
+	if s == "" {
+		s = fmt.Sprintf("event %d", e.ID)
+	}
+	if len(names) > 0 {
+		s += ": " + strings.Join(names, ", ")
+	}
+	if e.SiteID != -1 {
+		s += " at " + w.siteName(e.SiteID)
+	}
+	return s
+}
+
+// figureName, siteName and entityName return the name of a record or a
+// placeholder if the ID is dangling.
+func (w *World) figureName(id int) string {
+	if f := w.Figure(id); f != nil {
+		return f.Name
+	}
+	return fmt.Sprintf("unknown figure %d", id)
+}
+
+func (w *World) siteName(id int) string {
+	if s := w.Site(id); s != nil {
+		return s.Name
+	}
+	return fmt.Sprintf("unknown site %d", id)
+}
+
+func (w *World) entityName(id int) string {
+	if e := w.Entity(id); e != nil {
+		return e.Name
+	}
+	return fmt.Sprintf("unknown entity %d", id)
+}
+
 func (w *World) String() string {
 	buf := bytes.NewBuffer(nil)
+	if w.Name != "" {
+		fmt.Fprintf(buf, "%s, %s\n", w.Name, w.AltName)
+	}
+	buf.WriteString("Eras\n")
+	for _, s := range w.EraStats() {
+		if s.Era != nil {
+			end := "present"
+			if s.Era.EndYear != -1 {
+				end = fmt.Sprint(s.Era.EndYear)
+			}
+			fmt.Fprintf(buf, "%-30s %6d-%-7s ", s.Era.Name, s.Era.StartYear, end)
+		}
+		fmt.Fprintf(buf, "events:%d births:%d deaths:%d\n", s.Events, s.Births, s.Deaths)
+	}
 	buf.WriteString("Regions\n")
 	for _, r := range w.Regions {
 		fmt.Fprintf(buf, "%-3d %-30s %s\n", r.ID, r.Name, r.Type)
@@ -124,14 +331,41 @@ type UndergroundRegion struct {
 }
 
 type Site struct {
-	ID     int    `xml:"id" json:"id"`
-	Type   string `xml:"type" json:"type"`
-	Name   string `xml:"name" json:"name"`
-	Coords string `xml:"coords" json:"coords"`
-	//TODO is this unused?!
-	//Structures []*Structure `xml:"structures
+	ID         int          `xml:"id" json:"id"`
+	Type       string       `xml:"type" json:"type"`
+	Name       string       `xml:"name" json:"name"`
+	Coords     string       `xml:"coords" json:"coords"`
+	Structures []*Structure `xml:"structures>structure" json:"structures,omitempty"`
+
+	// CivID and CurOwnerID are only set by legends_plus.xml; -1 if unknown.
+	CivID      int `xml:"civ_id" json:"civ_id"`
+	CurOwnerID int `xml:"cur_owner_id" json:"cur_owner_id"`
+
+	// Owners and Residents are derived from events when the World is loaded.
+	Owners    []*SiteOwner `xml:"-" json:"owners,omitempty"`
+	Residents []*Resident  `xml:"-" json:"residents,omitempty"`
+
+	// Population is only set by world_sites_and_pops.txt
+	Population []*Population `xml:"-" json:"population,omitempty"`
 }
 
+func (s *Site) String() string { return s.Name }
+
+type Structure struct {
+	// legends.xml uses local_id and legends_plus.xml uses id
+	ID       int    `xml:"local_id" json:"id"`
+	PlusID   int    `xml:"id" json:"-"`
+	Type     string `xml:"type" json:"type"`
+	Name     string `xml:"name" json:"name"`
+	Name2    string `xml:"name2" json:"name2,omitempty"`
+	EntityID int    `xml:"entity_id" json:"entity_id"`
+
+	// WorshipFigureID is set for temples
+	WorshipFigureID int `xml:"worship_hfid" json:"worship_hfid"`
+}
+
+func (s *Structure) String() string { return s.Name }
+
 type Artifact struct {
 	ID   int    `xml:"id" json:"id"`
 	Name string `xml:"name" json:"name"`
@@ -146,10 +380,43 @@ type Figure struct {
 	Appeared   int           `xml:"appeared" json:"appeared"`
 	BirthYear  int           `xml:"birth_year" json:"birth_year"`
 	DeathYear  int           `xml:"death_year" json:"death_year"`
-	AssocTypes string        `xml:"associated_types" json:"associated_types"`
+	AssocTypes string        `xml:"associated_type" json:"associated_types"`
 	Entities   []*EntityLink `xml:"entity_link" json:"entity_link"`
 	Sites      []*SiteLink   `xml:"site_link" json:"site_link"`
 	Spheres    []string      `xml:"sphere" json:"sphere"`
+
+	// Deity, Force and Ghost are empty elements in legends.xml.
+	Deity Flag `xml:"deity" json:"deity,omitempty"`
+	Force Flag `xml:"force" json:"force,omitempty"`
+	Ghost Flag `xml:"ghost" json:"ghost,omitempty"`
+	// ActiveInteractions are the curses and secrets affecting the figure,
+	// e.g. DEITY_CURSE_VAMPIRE_1.
+	ActiveInteractions []string `xml:"active_interaction" json:"active_interaction,omitempty"`
+
+	Links              []*FigureLink        `xml:"hf_link" json:"hf_link,omitempty"`
+	VagueRelationships []*VagueRelationship `xml:"vague_relationship" json:"vague_relationship,omitempty"`
+	// RelationshipProfiles are of figures met in person and
+	// HistoricalProfiles of figures only heard of.
+	RelationshipProfiles []*RelationshipProfile `xml:"relationship_profile_hf_visual" json:"relationship_profile_hf_visual,omitempty"`
+	HistoricalProfiles   []*RelationshipProfile `xml:"relationship_profile_hf_historical" json:"relationship_profile_hf_historical,omitempty"`
+
+	// Tags classify supernatural figures, see FigureTags.
+	Tags []string `xml:"-" json:"tags,omitempty"`
+
+	// The rest is derived from events when the World is loaded. IDs are -1
+	// and Age is -1 when unknown.
+	Alive bool `xml:"-" json:"alive"`
+	// DiedIn is DeathYear or, if the export doesn't say, the year of the
+	// figure's death event.
+	DiedIn int `xml:"-" json:"died_in"`
+	// Age is the age at death or in the world's current year.
+	Age        int    `xml:"-" json:"age"`
+	DeathCause string `xml:"-" json:"death_cause,omitempty"`
+	// KillerID is the figure who slew this one.
+	KillerID    int `xml:"-" json:"killer_hfid"`
+	DeathSiteID int `xml:"-" json:"death_site_id"`
+	// ResidenceID is the site the figure lives at or lived at when it died.
+	ResidenceID int `xml:"-" json:"residence_site_id"`
 }
 
 func (f *Figure) String() string { return f.Name }
@@ -174,6 +441,29 @@ type EntityPopulation struct {
 type Entity struct {
 	ID   int    `xml:"id" json:"id"`
 	Name string `xml:"name" json:"name"`
+
+	// Race, Type and WorshipIDs are only set by legends_plus.xml
+	Race       string `xml:"race" json:"race,omitempty"`
+	Type       string `xml:"type" json:"type,omitempty"`
+	WorshipIDs []int  `xml:"worship_id" json:"worship_id,omitempty"`
+
+	// Leaders is only set by world_history.txt
+	Leaders []*Leader `xml:"-" json:"leaders,omitempty"`
+}
+
+func (e *Entity) String() string { return e.Name }
+
+// merge fields only present in legends_plus.xml into e
+func (e *Entity) merge(p *Entity) {
+	if p.Race != "" {
+		e.Race = p.Race
+	}
+	if p.Type != "" {
+		e.Type = p.Type
+	}
+	if len(p.WorshipIDs) > 0 {
+		e.WorshipIDs = p.WorshipIDs
+	}
 }
 
 type Event struct {
@@ -190,9 +480,58 @@ type Event struct {
 	// DefenderCivID is set when Type=destroyed_site
 	DefenderCivID int `xml:"defender_civ_id" json:"defender_civ_id"`
 
+	// CivID is set when Type=created site,reclaim site
+	CivID int `xml:"civ_id" json:"civ_id"`
+
+	// NewSiteCivID is set when Type=site taken over
+	NewSiteCivID int `xml:"new_site_civ_id" json:"new_site_civ_id"`
+
 	FigureID       int `xml:"hfid" json:"hfid"`
 	SlayerFigureID int `xml:"slayer_hfid" json:"slayer_hfid"`
-	SlayerItemID   int `xml:"slayer_item_id", json:"slayer_item_id"`
+	SlayerItemID   int `xml:"slayer_item_id" json:"slayer_item_id"`
+
+	// DoerFigureID, TargetFigureID and Interaction are set when Type=hf does
+	// interaction, e.g. a deity cursing a figure with DEITY_CURSE_VAMPIRE_1.
+	DoerFigureID   int    `xml:"doer_hfid" json:"doer_hfid"`
+	TargetFigureID int    `xml:"target_hfid" json:"target_hfid"`
+	Interaction    string `xml:"interaction" json:"interaction,omitempty"`
+
+	// HistFigureID and ArtifactID are set by artifact events; the figure
+	// made, stored or holds the artifact depending on the type.
+	HistFigureID int `xml:"hist_figure_id" json:"hist_figure_id"`
+	ArtifactID   int `xml:"artifact_id" json:"artifact_id"`
+
+	// SnatcherFigureID abducted TargetFigureID when Type=hf abducted
+	SnatcherFigureID int `xml:"snatcher_hfid" json:"snatcher_hfid"`
+
+	// WoundeeFigureID and WounderFigureID are set when Type=hf wounded
+	WoundeeFigureID int `xml:"woundee_hfid" json:"woundee_hfid"`
+	WounderFigureID int `xml:"wounder_hfid" json:"wounder_hfid"`
+
+	// Group1FigureID and Group2FigureID fought when Type=hf simple battle
+	// event
+	Group1FigureID int `xml:"group_1_hfid" json:"group_1_hfid"`
+	Group2FigureID int `xml:"group_2_hfid" json:"group_2_hfid"`
+
+	// HFTargetID is the target of events which name it hfid_target instead
+	// of target_hfid, e.g. Type=hf relationship denied
+	HFTargetID int `xml:"hfid_target" json:"hfid_target"`
+
+	// AttackerFigureID and DefenderFigureID are set by figures attacking or
+	// destroying sites and fighting each other
+	AttackerFigureID int `xml:"attacker_hfid" json:"attacker_hfid"`
+	DefenderFigureID int `xml:"defender_hfid" json:"defender_hfid"`
+
+	// GroupFigureID is set when Type=hf reach summit, hf travel, etc.
+	GroupFigureID int `xml:"group_hfid" json:"group_hfid"`
+
+	// TricksterFigureID fooled TargetEntityID when Type=assume identity
+	TricksterFigureID int `xml:"trickster_hfid" json:"trickster_hfid"`
+	TargetEntityID    int `xml:"target_enid" json:"target_enid"`
+
+	// EntityID is set when Type=entity created and by other events of a
+	// single entity
+	EntityID int `xml:"entity_id" json:"entity_id"`
 
 	// State values: visiting,settled,wandering
 	State string `xml:"state" json:"state,omitempty"`
@@ -0,0 +1,186 @@
+package lg
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Reference subject types used by WrittenContent.References
+const (
+	RefFigure         = "HISTORICAL_FIGURE"
+	RefSite           = "SITE"
+	RefEntity         = "ENTITY"
+	RefArtifact       = "ARTIFACT"
+	RefEvent          = "HISTORICAL_EVENT"
+	RefWrittenContent = "WRITTEN_CONTENT"
+	RefPoeticForm     = "POETIC_FORM"
+	RefMusicalForm    = "MUSICAL_FORM"
+	RefDanceForm      = "DANCE_FORM"
+)
+
+type WrittenContent struct {
+	ID    int    `xml:"id" json:"id"`
+	Title string `xml:"title" json:"title"`
+
+	// legends.xml uses author_hfid and legends_plus.xml uses author
+	AuthorFigureID int `xml:"author_hfid" json:"author_hfid"`
+	PlusAuthorID   int `xml:"author" json:"-"`
+
+	// Form values: poem,musical composition,choreography,...
+	Form   string `xml:"form" json:"form,omitempty"`
+	FormID int    `xml:"form_id" json:"form_id"`
+
+	// Type is only set by legends_plus.xml
+	Type       string       `xml:"type" json:"type,omitempty"`
+	PageStart  int          `xml:"page_start" json:"page_start,omitempty"`
+	PageEnd    int          `xml:"page_end" json:"page_end,omitempty"`
+	Styles     []string     `xml:"style" json:"style,omitempty"`
+	References []*Reference `xml:"reference" json:"reference,omitempty"`
+}
+
+func (c *WrittenContent) String() string { return c.Title }
+
+func (c *WrittenContent) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type writtenContent WrittenContent
+	v := writtenContent{AuthorFigureID: -1, PlusAuthorID: -1, FormID: -1}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	if v.AuthorFigureID == -1 {
+		v.AuthorFigureID = v.PlusAuthorID
+	}
+	*c = WrittenContent(v)
+	return nil
+}
+
+// merge fields only present in legends_plus.xml into c
+func (c *WrittenContent) merge(p *WrittenContent) {
+	if c.Title == "" {
+		c.Title = p.Title
+	}
+	if c.AuthorFigureID == -1 {
+		c.AuthorFigureID = p.AuthorFigureID
+	}
+	c.Type = p.Type
+	c.PageStart = p.PageStart
+	c.PageEnd = p.PageEnd
+	if len(c.Styles) == 0 {
+		c.Styles = p.Styles
+	}
+	if len(c.References) == 0 {
+		c.References = p.References
+	}
+}
+
+// Reference is a subject a WrittenContent is about. Type is one of the Ref*
+// constants (or another DF reference type).
+type Reference struct {
+	Type string `xml:"type" json:"type"`
+	ID   int    `xml:"id" json:"id"`
+}
+
+// Form is a poetic, musical or dance form.
+type Form struct {
+	ID int `xml:"id" json:"id"`
+
+	// Name is only set by legends_plus.xml
+	Name        string `xml:"name" json:"name,omitempty"`
+	Description string `xml:"description" json:"description,omitempty"`
+}
+
+func (f *Form) String() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	// Descriptions start with the form's name: "The Ode of Stone is a..."
+	if i := strings.Index(f.Description, " is a"); i > 0 {
+		return f.Description[:i]
+	}
+	return f.Description
+}
+
+func mergeForms(forms []*Form, plus []*Form) []*Form {
+	for _, p := range plus {
+		f := findForm(forms, p.ID)
+		if f == nil {
+			forms = append(forms, p)
+			continue
+		}
+		if f.Name == "" {
+			f.Name = p.Name
+		}
+		if f.Description == "" {
+			f.Description = p.Description
+		}
+	}
+	return forms
+}
+
+func findForm(forms []*Form, id int) *Form {
+	for _, f := range forms {
+		if f.ID == id {
+			return f
+		}
+	}
+	return nil
+}
+
+func (w *World) WrittenContent(id int) *WrittenContent {
+	return w.wcidx[id]
+}
+
+func (w *World) PoeticForm(id int) *Form  { return findForm(w.PoeticForms, id) }
+func (w *World) MusicalForm(id int) *Form { return findForm(w.MusicalForms, id) }
+func (w *World) DanceForm(id int) *Form   { return findForm(w.DanceForms, id) }
+
+// WrittenContentForm returns the poetic, musical or dance form c was written
+// in or nil.
+func (w *World) WrittenContentForm(c *WrittenContent) *Form {
+	switch c.Form {
+	case "poem":
+		return w.PoeticForm(c.FormID)
+	case "musical composition":
+		return w.MusicalForm(c.FormID)
+	case "choreography":
+		return w.DanceForm(c.FormID)
+	}
+	return nil
+}
+
+// FigureWrittenContents returns the works authored by a figure.
+func (w *World) FigureWrittenContents(id int) []*WrittenContent {
+	return w.authoridx[id]
+}
+
+// WrittenAbout returns the works referencing a subject of the given Ref*
+// type.
+func (w *World) WrittenAbout(typ string, id int) []*WrittenContent {
+	return w.refidx[Reference{Type: typ, ID: id}]
+}
+
+// FormWrittenContents returns the works written in a form. kind is "poem",
+// "musical composition" or "choreography".
+func (w *World) FormWrittenContents(kind string, id int) []*WrittenContent {
+	var out []*WrittenContent
+	for _, c := range w.WrittenContents {
+		if c.Form == kind && c.FormID == id {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (w *World) initWrittenContents() {
+	w.wcidx = make(map[int]*WrittenContent, len(w.WrittenContents))
+	w.authoridx = make(map[int][]*WrittenContent)
+	w.refidx = make(map[Reference][]*WrittenContent)
+	for _, c := range w.WrittenContents {
+		w.wcidx[c.ID] = c
+		if c.AuthorFigureID != -1 {
+			w.authoridx[c.AuthorFigureID] = append(w.authoridx[c.AuthorFigureID], c)
+		}
+		for _, r := range c.References {
+			w.refidx[*r] = append(w.refidx[*r], c)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package lg
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Population is the number of a race living in the world or a site.
+type Population struct {
+	Race  string `json:"race"`
+	Count int    `json:"count"`
+}
+
+var (
+	popSite  = regexp.MustCompile(`^(\d+): (.*)$`)
+	popCount = regexp.MustCompile(`^(\d+) (.+)$`)
+)
+
+// MergeSitesAndPops parses the world_sites_and_pops.txt DF exports from
+// legends mode and sets the population of the world and its sites.
+func (w *World) MergeSitesAndPops(r io.Reader) error {
+	const (
+		civilized = iota
+		sites
+		other
+	)
+	section := other
+	w.Populations = w.Populations[:0]
+	var site *Site
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		raw := strings.TrimRight(s.Text(), " \r")
+		line := strings.TrimSpace(raw)
+		indented := line != raw
+		switch {
+		case line == "":
+			continue
+		case line == "Civilized World Population":
+			section = civilized
+			continue
+		case line == "Sites":
+			section = sites
+			continue
+		case !indented && section == sites && !popSite.MatchString(line):
+			// Animal populations follow the sites
+			section, site = other, nil
+			continue
+		}
+
+		switch section {
+		case civilized:
+			if m := popCount.FindStringSubmatch(line); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				w.Populations = append(w.Populations, &Population{Race: m[2], Count: n})
+			}
+		case sites:
+			if m := popSite.FindStringSubmatch(line); m != nil && !indented {
+				id, _ := strconv.Atoi(m[1])
+				if site = w.Site(id); site != nil {
+					site.Population = site.Population[:0]
+				}
+				continue
+			}
+			if site == nil {
+				continue
+			}
+			if m := popCount.FindStringSubmatch(line); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				site.Population = append(site.Population, &Population{Race: m[2], Count: n})
+			}
+		}
+	}
+	return s.Err()
+}
+
+// TotalPopulation returns the sum of pops.
+func TotalPopulation(pops []*Population) int {
+	n := 0
+	for _, p := range pops {
+		n += p.Count
+	}
+	return n
+}
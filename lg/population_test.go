@@ -0,0 +1,91 @@
+package lg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const populationWorld = `<df_world>
+<sites>
+<site><id>1</id><name>boltwheels</name></site>
+<site><id>2</id><name>tulipdew</name></site>
+</sites>
+</df_world>`
+
+func TestMergeSitesAndPops(t *testing.T) {
+	cases := []struct {
+		name  string
+		pops  string
+		world []*Population
+		sites map[int][]*Population
+	}{
+		{
+			name: "populations",
+			pops: `Civilized World Population
+
+ 120 Dwarves
+ 40 Goblins
+Total: 160
+
+Sites
+
+1: Boltwheels, "Boltwheels", fortress
+	Owner: The Realm Of Copper, dwarves
+	33 dwarves
+	2 goblins
+	Total: 35
+2: Tulipdew, "Tulipdew", hamlet
+	Total: 0
+
+Outdoor Animal Populations (Including Undead)
+
+ Unnumbered Giant Eagles
+	5 in the hills
+`,
+			world: []*Population{{"Dwarves", 120}, {"Goblins", 40}},
+			sites: map[int][]*Population{1: {{"dwarves", 33}, {"goblins", 2}}},
+		},
+		{
+			name:  "unknown site",
+			pops:  "Sites\n\n9: Nowhere, \"Nowhere\", cave\n\t4 trolls\n2: Tulipdew, \"Tulipdew\", hamlet\n\t6 elves\n",
+			sites: map[int][]*Population{2: {{"elves", 6}}},
+		},
+		{
+			name:  "crlf",
+			pops:  "Civilized World Population\r\n\r\n 3 Humans\r\nSites\r\n1: Boltwheels\r\n\t1 human\r\n",
+			world: []*Population{{"Humans", 3}},
+			sites: map[int][]*Population{1: {{"human", 1}}},
+		},
+		{
+			name:  "truncated",
+			pops:  "Civilized World Population\n\n 120 Dwarves\n 40 Gob",
+			world: []*Population{{"Dwarves", 120}, {"Gob", 40}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := testWorld(t, populationWorld)
+			if err := w.MergeSitesAndPops(strings.NewReader(c.pops)); err != nil {
+				t.Fatalf("MergeSitesAndPops: %v", err)
+			}
+			if len(w.Populations)+len(c.world) > 0 && !reflect.DeepEqual(w.Populations, c.world) {
+				t.Errorf("world populations = %+v, want %+v", w.Populations, c.world)
+			}
+			for _, s := range w.Sites {
+				if want := c.sites[s.ID]; len(s.Population)+len(want) > 0 && !reflect.DeepEqual(s.Population, want) {
+					t.Errorf("site %d populations = %+v, want %+v", s.ID, s.Population, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTotalPopulation(t *testing.T) {
+	if n := TotalPopulation([]*Population{{"dwarves", 33}, {"goblins", 2}}); n != 35 {
+		t.Errorf("TotalPopulation = %d, want 35", n)
+	}
+	if n := TotalPopulation(nil); n != 0 {
+		t.Errorf("TotalPopulation(nil) = %d, want 0", n)
+	}
+}
@@ -0,0 +1,194 @@
+package lg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// DecodeError is where a RecordDecoder stopped decoding.
+type DecodeError struct {
+	Section string // xml element of the World section or "" outside one
+	Records int    // records decoded from Section before the error
+	Line    int
+	Column  int
+	Offset  int64 // byte offset in the decoded (UTF-8) xml
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	where := fmt.Sprintf("line %d column %d (offset %d)", e.Line, e.Column, e.Offset)
+	if e.Section != "" {
+		where = fmt.Sprintf("%s after %d records at %s", e.Section, e.Records, where)
+	}
+	return fmt.Sprintf("stopped decoding in %s: %v", where, e.Err)
+}
+
+// RecordDecoder decodes legends xml into a World record by record, reporting
+// each record to OnRecord. Errors are returned as a *DecodeError with the
+// position decoding stopped at.
+type RecordDecoder struct {
+	// Lenient keeps everything decoded before an error (usually a truncated
+	// file) instead of returning it; check Err after decoding.
+	Lenient bool
+
+	// OnRecord is called with the section's xml element after each record
+	// is decoded if it's not nil.
+	OnRecord func(section string)
+
+	d   *xml.Decoder
+	err *DecodeError
+}
+
+func NewRecordDecoder(d *xml.Decoder) *RecordDecoder {
+	return &RecordDecoder{d: d}
+}
+
+// NewXMLDecoder returns a RecordDecoder for legends xml as DF writes it:
+// invalid xml is fixed by a Sanitizer and CP437 is converted to UTF-8
+// regardless of the encoding the xml declares.
+func NewXMLDecoder(r io.Reader) *RecordDecoder {
+	d := xml.NewDecoder(charmap.CodePage437.NewDecoder().Reader(NewSanitizer(r)))
+	d.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) {
+		// Already converted
+		return r, nil
+	}
+	return NewRecordDecoder(d)
+}
+
+// Err returns where decoding stopped or nil if the whole document was
+// decoded.
+func (l *RecordDecoder) Err() *DecodeError { return l.err }
+
+// worldSection is a World field decoded from a top level element. Lists
+// (tagged "section>item") have an item element; other fields don't.
+type worldSection struct {
+	field int
+	item  string
+}
+
+func worldSections() map[string]*worldSection {
+	t := reflect.TypeOf(World{})
+	sections := map[string]*worldSection{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("xml"), ",")[0]
+		if sf.PkgPath != "" || tag == "" || tag == "-" || sf.Name == "XMLName" {
+			continue
+		}
+		parts := strings.SplitN(tag, ">", 2)
+		s := &worldSection{field: i}
+		if len(parts) == 2 {
+			s.item = parts[1]
+		}
+		sections[parts[0]] = s
+	}
+	return sections
+}
+
+// Decode v, which must be a *World.
+func (l *RecordDecoder) Decode(v interface{}) error {
+	w, ok := v.(*World)
+	if !ok {
+		return fmt.Errorf("record decoding unsupported for %T", v)
+	}
+	sections := worldSections()
+	wv := reflect.ValueOf(w).Elem()
+	depth := 0
+	for {
+		tok, err := l.d.Token()
+		if err == io.EOF && depth == 0 {
+			return nil
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return l.stop("", 0, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				// df_world
+				depth++
+				continue
+			}
+			s := sections[t.Name.Local]
+			if s == nil {
+				if err := l.d.Skip(); err != nil {
+					return l.stop(t.Name.Local, 0, err)
+				}
+				continue
+			}
+			if n, err := l.section(wv.Field(s.field), s, &t); err != nil {
+				return l.stop(t.Name.Local, n, err)
+			}
+		case xml.EndElement:
+			if depth--; depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// section decodes the element start into field a record at a time and
+// returns the number of records decoded.
+func (l *RecordDecoder) section(field reflect.Value, s *worldSection, start *xml.StartElement) (int, error) {
+	if s.item == "" {
+		return 0, l.d.DecodeElement(field.Addr().Interface(), start)
+	}
+	n := 0
+	for {
+		tok, err := l.d.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != s.item {
+				if err := l.d.Skip(); err != nil {
+					return n, err
+				}
+				continue
+			}
+			rec := reflect.New(field.Type().Elem().Elem())
+			if err := l.d.DecodeElement(rec.Interface(), &t); err != nil {
+				return n, err
+			}
+			field.Set(reflect.Append(field, rec))
+			n++
+			if l.OnRecord != nil {
+				l.OnRecord(start.Name.Local)
+			}
+		case xml.EndElement:
+			return n, nil
+		}
+	}
+}
+
+// stop records where decoding stopped and returns the error unless lenient.
+func (l *RecordDecoder) stop(section string, records int, err error) error {
+	line, col := l.d.InputPos()
+	if serr, ok := err.(*xml.SyntaxError); ok {
+		line, err = serr.Line, fmt.Errorf("%s", serr.Msg)
+	}
+	l.err = &DecodeError{
+		Section: section,
+		Records: records,
+		Line:    line,
+		Column:  col,
+		Offset:  l.d.InputOffset(),
+		Err:     err,
+	}
+	if l.Lenient {
+		return nil
+	}
+	return l.err
+}
@@ -0,0 +1,109 @@
+package lg
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+const recordsWorld = `<df_world>
+<regions>
+<region><id>0</id><name>the plains</name><type>Grassland</type></region>
+</regions>
+<sites>
+<site><id>1</id><name>boltwheels</name></site>
+<site><id>2</id><name>tulipdew</name></site>
+</sites>
+<historical_figures>
+<historical_figure><id>7</id><name>urist</name></historical_figure>
+</historical_figures>
+</df_world>`
+
+func TestRecordDecoder(t *testing.T) {
+	cases := []struct {
+		name    string
+		doc     string
+		lenient bool
+		sites   int
+		figures int
+		err     *DecodeError // only Section, Records and the Err message are compared
+	}{
+		{name: "complete", doc: recordsWorld, sites: 2, figures: 1},
+		{name: "complete lenient", doc: recordsWorld, lenient: true, sites: 2, figures: 1},
+		{
+			name: "truncated record", doc: recordsWorld[:strings.Index(recordsWorld, "tulipdew")],
+			err: &DecodeError{Section: "sites", Records: 1},
+		},
+		{
+			name: "truncated record lenient", doc: recordsWorld[:strings.Index(recordsWorld, "tulipdew")], lenient: true, sites: 1,
+			err: &DecodeError{Section: "sites", Records: 1},
+		},
+		{
+			name: "truncated between sections", doc: recordsWorld[:strings.Index(recordsWorld, "<historical_figures>")], lenient: true, sites: 2,
+			err: &DecodeError{Err: io.ErrUnexpectedEOF},
+		},
+		{
+			name: "truncated section", doc: recordsWorld[:strings.Index(recordsWorld, "</historical_figures>")], lenient: true, sites: 2, figures: 1,
+			err: &DecodeError{Section: "historical_figures", Records: 1, Err: io.ErrUnexpectedEOF},
+		},
+		{
+			name: "unknown entity", doc: strings.Replace(recordsWorld, "urist", "urist&nbsp;", 1), lenient: true, sites: 2,
+			err: &DecodeError{Section: "historical_figures"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := NewRecordDecoder(xml.NewDecoder(strings.NewReader(c.doc)))
+			d.Lenient = c.lenient
+			records := map[string]int{}
+			d.OnRecord = func(section string) { records[section]++ }
+
+			w := &World{}
+			err := d.Decode(w)
+			switch {
+			case c.err == nil && err != nil:
+				t.Fatalf("Decode: %v", err)
+			case c.err != nil && !c.lenient && err == nil:
+				t.Fatalf("Decode succeeded, want %+v", c.err)
+			case c.lenient && err != nil:
+				t.Fatalf("lenient Decode: %v", err)
+			}
+			if c.err == nil {
+				if d.Err() != nil {
+					t.Errorf("Err = %v, want nil", d.Err())
+				}
+			} else if got := d.Err(); got == nil {
+				t.Errorf("Err = nil, want %+v", c.err)
+			} else if got.Section != c.err.Section || got.Records != c.err.Records || c.err.Err != nil && got.Err.Error() != c.err.Err.Error() {
+				t.Errorf("Err = %+v, want %+v", got, c.err)
+			}
+			if c.lenient || c.err == nil {
+				if len(w.Sites) != c.sites || len(w.Figures) != c.figures {
+					t.Errorf("decoded %d sites and %d figures, want %d and %d", len(w.Sites), len(w.Figures), c.sites, c.figures)
+				}
+				if records["sites"] != c.sites || records["historical_figures"] != c.figures {
+					t.Errorf("OnRecord counts = %v, want %d sites and %d figures", records, c.sites, c.figures)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordDecoderUnsupported(t *testing.T) {
+	d := NewRecordDecoder(xml.NewDecoder(strings.NewReader(recordsWorld)))
+	if err := d.Decode(&Site{}); err == nil {
+		t.Error("decoding a Site succeeded, want an error")
+	}
+}
+
+func TestNewXMLDecoder(t *testing.T) {
+	doc := "<?xml version=\"1.0\" encoding='UTF-8'?>\n<df_world><sites><site><id>1</id><name>\x82boltwheels & co\x01</name></site></sites></df_world>"
+	w, err := New(NewXMLDecoder(strings.NewReader(doc)))
+	if err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	if name, want := w.Site(1).Name, "éboltwheels & co "; name != want {
+		t.Errorf("site name = %q, want %q", name, want)
+	}
+}
@@ -0,0 +1,174 @@
+package lg
+
+import (
+	"encoding/xml"
+	"slices"
+	"strings"
+)
+
+// Relationship is a typed link between two figures: To is From's Type, e.g.
+// To is From's "apprentice" or "deity", like hf_link in legends.xml.
+type Relationship struct {
+	Type   string `json:"type"`
+	FromID int    `json:"from_hfid"`
+	ToID   int    `json:"to_hfid"`
+	// Year the relationship formed or -1 if unknown.
+	Year int `json:"year"`
+	// Strength is the link_strength of hf_links or the reputation of
+	// relationship profiles.
+	Strength int `json:"strength,omitempty"`
+	// Source is the element of the export it was read from.
+	Source string `json:"source"`
+}
+
+// Other returns the ID of the figure at the other end of r from id.
+func (r *Relationship) Other(id int) int {
+	if r.FromID == id {
+		return r.ToID
+	}
+	return r.FromID
+}
+
+// FigureLink is an hf_link: the figure ID is the figure's Type, e.g. child,
+// deity or master.
+type FigureLink struct {
+	Type     string `xml:"link_type" json:"link_type"`
+	ID       int    `xml:"hfid" json:"hfid"`
+	Strength int    `xml:"link_strength" json:"link_strength,omitempty"`
+}
+
+// RelationshipProfile is what a figure knows of another it met or heard of.
+type RelationshipProfile struct {
+	ID        int    `xml:"hf_id" json:"hfid"`
+	MeetCount int    `xml:"meet_count" json:"meet_count,omitempty"`
+	LastMeet  int    `xml:"last_meet_year" json:"last_meet_year"`
+	KnownAs   string `xml:"known_as" json:"known_as,omitempty"`
+	Friendly  int    `xml:"rep_friendly" json:"rep_friendly,omitempty"`
+	Love      int    `xml:"love" json:"love,omitempty"`
+	Trust     int    `xml:"trust" json:"trust,omitempty"`
+	Fear      int    `xml:"fear" json:"fear,omitempty"`
+}
+
+func (p *RelationshipProfile) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type profile RelationshipProfile
+	v := profile{ID: -1, LastMeet: -1}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*p = RelationshipProfile(v)
+	return nil
+}
+
+// VagueRelationship is a vague_relationship such as war buddies. Its Type is
+// the name of its empty element with underscores as spaces.
+type VagueRelationship struct {
+	Type string `json:"type"`
+	ID   int    `json:"hfid"`
+}
+
+func (v *VagueRelationship) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var rel struct {
+		ID    int `xml:"hfid"`
+		Flags []struct {
+			XMLName xml.Name
+		} `xml:",any"`
+	}
+	if err := d.DecodeElement(&rel, &start); err != nil {
+		return err
+	}
+	v.ID = rel.ID
+	if len(rel.Flags) > 0 {
+		v.Type = strings.ReplaceAll(rel.Flags[0].XMLName.Local, "_", " ")
+	}
+	return nil
+}
+
+// EventRelationship is a relationship legends_plus.xml records with the
+// event that formed it.
+type EventRelationship struct {
+	EventID  int    `xml:"event" json:"event_id"`
+	Type     string `xml:"relationship" json:"relationship"`
+	SourceID int    `xml:"source_hf" json:"source_hf"`
+	TargetID int    `xml:"target_hf" json:"target_hf"`
+	Year     int    `xml:"year" json:"year"`
+}
+
+// initRelationships builds the relationship graph from the figures' links
+// and relationships and legends_plus.xml's event relationships.
+func (w *World) initRelationships() {
+	w.relidx = map[int][]*Relationship{}
+	add := func(r *Relationship) {
+		if w.Figure(r.FromID) == nil || w.Figure(r.ToID) == nil || r.FromID == r.ToID {
+			return
+		}
+		w.relidx[r.FromID] = append(w.relidx[r.FromID], r)
+		w.relidx[r.ToID] = append(w.relidx[r.ToID], r)
+	}
+	for _, f := range w.Figures {
+		for _, l := range f.Links {
+			add(&Relationship{Type: l.Type, FromID: f.ID, ToID: l.ID, Year: -1, Strength: l.Strength, Source: "hf_link"})
+		}
+		for _, v := range f.VagueRelationships {
+			add(&Relationship{Type: v.Type, FromID: f.ID, ToID: v.ID, Year: -1, Source: "vague_relationship"})
+		}
+		for _, p := range f.RelationshipProfiles {
+			add(&Relationship{Type: p.Type(), FromID: f.ID, ToID: p.ID, Year: p.LastMeet, Strength: p.Friendly, Source: "relationship_profile_hf_visual"})
+		}
+		for _, p := range f.HistoricalProfiles {
+			add(&Relationship{Type: p.Type(), FromID: f.ID, ToID: p.ID, Year: -1, Strength: p.Friendly, Source: "relationship_profile_hf_historical"})
+		}
+	}
+	for _, er := range w.EventRelationships {
+		add(&Relationship{Type: er.Type, FromID: er.SourceID, ToID: er.TargetID, Year: er.Year, Source: "historical_event_relationship"})
+	}
+}
+
+// Type returns "friend" or "grudge" if the figure likes or dislikes the
+// other and "acquaintance" otherwise.
+func (p *RelationshipProfile) Type() string {
+	switch {
+	case p.Friendly > 0 || p.Love > 0:
+		return "friend"
+	case p.Friendly < 0 || p.Love < 0:
+		return "grudge"
+	}
+	return "acquaintance"
+}
+
+// Relationships returns the relationships of figure id in either direction.
+// The slice is shared so callers must not modify its elements.
+func (w *World) Relationships(id int) []*Relationship {
+	return slices.Clip(w.relidx[id])
+}
+
+// SocialPath returns the shortest chain of relationships from figure from to
+// figure to, nil if they aren't connected or the same figure.
+func (w *World) SocialPath(from, to int) []*Relationship {
+	if from == to || w.Figure(from) == nil || w.Figure(to) == nil {
+		return nil
+	}
+	// via is the relationship each figure was first reached through
+	via := map[int]*Relationship{from: nil}
+	queue := []int{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, r := range w.relidx[id] {
+			next := r.Other(id)
+			if _, seen := via[next]; seen {
+				continue
+			}
+			via[next] = r
+			if next == to {
+				var path []*Relationship
+				for id := to; id != from; id = via[id].Other(id) {
+					path = append(path, via[id])
+				}
+				slices.Reverse(path)
+				return path
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package lg
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// NewSanitizer returns a reader which fixes the invalid xml DF writes in r:
+// control characters are replaced with spaces, stray ampersands and unknown
+// entities are escaped and character references to invalid characters are
+// replaced with spaces.
+//
+// It works on bytes so it must be used before decoding CP437.
+func NewSanitizer(r io.Reader) io.Reader {
+	return &sanitizer{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+type sanitizer struct {
+	r       *bufio.Reader
+	pending []byte
+}
+
+// maxEntity is the longest entity we look ahead for (&#x10FFFF;)
+const maxEntity = 10
+
+var xmlEntities = map[string]bool{"amp": true, "lt": true, "gt": true, "quot": true, "apos": true}
+
+func (s *sanitizer) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.pending) > 0 {
+			c := copy(p[n:], s.pending)
+			s.pending = s.pending[c:]
+			n += c
+			continue
+		}
+		b, err := s.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		switch {
+		case b == '&':
+			s.pending = s.entity()
+			continue
+		case b < 0x20 && b != '\t' && b != '\n' && b != '\r':
+			b = ' '
+		}
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
+
+// entity returns the replacement for the entity following an '&' and
+// consumes it.
+func (s *sanitizer) entity() []byte {
+	ahead, _ := s.r.Peek(maxEntity)
+	i := bytes.IndexByte(ahead, ';')
+	if i < 1 {
+		return []byte("&amp;")
+	}
+	name := string(ahead[:i])
+	if xmlEntities[name] {
+		return []byte("&")
+	}
+	if name[0] != '#' {
+		return []byte("&amp;")
+	}
+	var c int64
+	var err error
+	if len(name) > 1 && (name[1] == 'x' || name[1] == 'X') {
+		c, err = strconv.ParseInt(name[2:], 16, 32)
+	} else {
+		c, err = strconv.ParseInt(name[1:], 10, 32)
+	}
+	if err != nil {
+		return []byte("&amp;")
+	}
+	if validChar(rune(c)) {
+		return []byte("&")
+	}
+	s.r.Discard(i + 1)
+	return []byte(" ")
+}
+
+// validChar returns true if c is allowed in xml.
+func validChar(c rune) bool {
+	return c == 0x09 || c == 0x0A || c == 0x0D ||
+		c >= 0x20 && c <= 0xD7FF ||
+		c >= 0xE000 && c <= 0xFFFD ||
+		c >= 0x10000 && c <= 0x10FFFF
+}
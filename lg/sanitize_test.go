@@ -0,0 +1,54 @@
+package lg
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSanitizer(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{"valid", "<a b=\"x&amp;y\">&lt;&gt;&quot;&apos;\t\r\n</a>", "<a b=\"x&amp;y\">&lt;&gt;&quot;&apos;\t\r\n</a>"},
+		{"control chars", "a\x00b\x01c\x1bd\x1f", "a b c d "},
+		{"stray ampersand", "fish & chips", "fish &amp; chips"},
+		{"trailing ampersand", "fish &", "fish &amp;"},
+		{"ampersand semicolon", "&;", "&amp;;"},
+		{"semicolon too far", "&verylongname;", "&amp;verylongname;"},
+		{"unknown entity", "&nbsp;&eacute;", "&amp;nbsp;&amp;eacute;"},
+		{"numeric reference", "&#65;&#x42;&#X43;&#x10FFFF;", "&#65;&#x42;&#X43;&#x10FFFF;"},
+		{"invalid numeric reference", "a&#1;b&#x1F;c&#xFFFE;d", "a b c d"},
+		{"malformed numeric reference", "&#;&#x;&#12a;", "&amp;#;&amp;#x;&amp;#12a;"},
+		{"cp437 bytes", "\x82boltwheels", "\x82boltwheels"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// one byte reads make sure lookahead works across reads
+			for _, r := range []io.Reader{strings.NewReader(c.in), iotest.OneByteReader(strings.NewReader(c.in))} {
+				out, err := io.ReadAll(NewSanitizer(r))
+				if err != nil {
+					t.Fatalf("reading: %v", err)
+				}
+				if string(out) != c.out {
+					t.Errorf("sanitized %q = %q, want %q", c.in, out, c.out)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizerDecodes(t *testing.T) {
+	in := "<df_world><sites><site><id>1</id><name>fish & chips&#1;\x07&nbsp;</name></site></sites></df_world>"
+	w, err := New(xml.NewDecoder(NewSanitizer(strings.NewReader(in))))
+	if err != nil {
+		t.Fatalf("decoding sanitized xml: %v", err)
+	}
+	if name, want := w.Site(1).Name, "fish & chips  &nbsp;"; name != want {
+		t.Errorf("site name = %q, want %q", name, want)
+	}
+}
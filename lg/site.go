@@ -0,0 +1,153 @@
+package lg
+
+import "encoding/xml"
+
+// SiteOwner is a period of a Site's ownership. CivID is -1 while the site
+// lies in ruins and To is -1 if the owner still holds the site.
+type SiteOwner struct {
+	CivID     int `json:"civ_id"`
+	SiteCivID int `json:"site_civ_id"`
+	From      int `json:"from"`
+	To        int `json:"to"`
+	EventID   int `json:"event_id"`
+	event     *Event
+}
+
+// Event returns the event that began this period of ownership or nil if it is
+// only known from legends_plus.xml.
+func (o *SiteOwner) Event() *Event { return o.event }
+
+// Resident is a figure who settled at a Site. To is -1 if the figure never
+// left.
+type Resident struct {
+	FigureID int `json:"hfid"`
+	From     int `json:"from"`
+	To       int `json:"to"`
+}
+
+func (s *Site) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type site Site
+	v := site{CivID: -1, CurOwnerID: -1}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*s = Site(v)
+	return nil
+}
+
+func (s *Structure) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type structure Structure
+	v := structure{EntityID: -1, WorshipFigureID: -1}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*s = Structure(v)
+	return nil
+}
+
+// merge fields only present in legends_plus.xml into s
+func (s *Site) merge(p *Site) {
+	if p.CivID != -1 {
+		s.CivID = p.CivID
+	}
+	if p.CurOwnerID != -1 {
+		s.CurOwnerID = p.CurOwnerID
+	}
+	for _, ps := range p.Structures {
+		st := s.Structure(ps.ID)
+		if st == nil {
+			s.Structures = append(s.Structures, ps)
+			continue
+		}
+		if st.Name2 == "" {
+			st.Name2 = ps.Name2
+		}
+		if st.Type == "" {
+			st.Type = ps.Type
+		}
+	}
+}
+
+func (s *Site) Structure(id int) *Structure {
+	for _, st := range s.Structures {
+		if st.ID == id {
+			return st
+		}
+	}
+	return nil
+}
+
+// Owner returns the current owner of the site or nil if it's unowned or
+// unknown.
+func (s *Site) Owner() *SiteOwner {
+	if len(s.Owners) == 0 {
+		return nil
+	}
+	o := s.Owners[len(s.Owners)-1]
+	if o.CivID == -1 {
+		return nil
+	}
+	return o
+}
+
+// initSites derives site ownership and residents from events.
+func (w *World) initSites() {
+	for _, s := range w.Sites {
+		s.Owners = nil
+		s.Residents = nil
+	}
+
+	own := func(s *Site, e *Event, civ, siteciv int) {
+		if n := len(s.Owners); n > 0 {
+			s.Owners[n-1].To = e.Year
+		}
+		s.Owners = append(s.Owners, &SiteOwner{CivID: civ, SiteCivID: siteciv, From: e.Year, To: -1, EventID: e.ID, event: e})
+	}
+
+	// figure id -> current residence
+	residents := map[int]*Resident{}
+	leave := func(e *Event) {
+		if r := residents[e.FigureID]; r != nil {
+			r.To = e.Year
+			delete(residents, e.FigureID)
+		}
+	}
+
+	for _, e := range w.Events {
+		s := w.Site(e.SiteID)
+		switch e.Type {
+		case "created site":
+			if s != nil {
+				own(s, e, e.CivID, e.SiteCivID)
+			}
+		case "site taken over":
+			if s != nil {
+				own(s, e, e.AttackerCivID, e.NewSiteCivID)
+			}
+		case "destroyed site":
+			if s != nil {
+				own(s, e, -1, -1)
+			}
+		case "reclaim site":
+			if s != nil {
+				own(s, e, e.CivID, e.SiteCivID)
+			}
+		case "change hf state":
+			leave(e)
+			if s != nil && e.State == "settled" {
+				r := &Resident{FigureID: e.FigureID, From: e.Year, To: -1}
+				s.Residents = append(s.Residents, r)
+				residents[e.FigureID] = r
+			}
+		case "hf died":
+			leave(e)
+		}
+	}
+
+	// Sites without ownership events still have their owner in legends_plus
+	for _, s := range w.Sites {
+		if len(s.Owners) == 0 && s.CivID != -1 {
+			s.Owners = append(s.Owners, &SiteOwner{CivID: s.CivID, SiteCivID: s.CurOwnerID, From: -1, To: -1, EventID: -1})
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package lg
+
+import (
+	"bufio"
+	"encoding/gob"
+	"io"
+)
+
+// SnapshotMagic is the header of snapshots written by WriteSnapshot.
+// Snapshots are much faster to load than legends xml.
+const SnapshotMagic = "LGSNAP1\n"
+
+// WriteSnapshot writes a snapshot of w which can be loaded with
+// NewSnapshotDecoder.
+func (w *World) WriteSnapshot(out io.Writer) error {
+	bw := bufio.NewWriter(out)
+	if _, err := bw.WriteString(SnapshotMagic); err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(bw).Encode(w); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// NewSnapshotDecoder returns a Decoder for a snapshot written by
+// WriteSnapshot.
+func NewSnapshotDecoder(r io.Reader) (Decoder, error) {
+	magic := make([]byte, len(SnapshotMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != SnapshotMagic {
+		return nil, errBadSnapshot
+	}
+	return gob.NewDecoder(r), nil
+}
+
+type snapshotError string
+
+func (e snapshotError) Error() string { return string(e) }
+
+const errBadSnapshot = snapshotError("not a legendarygopher snapshot")
@@ -0,0 +1,334 @@
+package lg
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Problem kinds found by Validate
+const (
+	ProblemDangling  = "dangling reference"
+	ProblemDuplicate = "duplicate id"
+	ProblemDate      = "impossible date"
+	ProblemUnknown   = "unknown value"
+)
+
+// maxExamples is the number of example problems kept per check
+const maxExamples = 5
+
+// Problem is a single invalid value in a record.
+type Problem struct {
+	Record  string `json:"record"`
+	Message string `json:"message"`
+}
+
+// Check is every problem of one kind in one field of a section.
+type Check struct {
+	Kind     string     `json:"kind"`
+	Section  string     `json:"section"`
+	Field    string     `json:"field"`
+	Count    int        `json:"count"`
+	Examples []*Problem `json:"examples"`
+}
+
+// Report is the result of validating a World.
+type Report struct {
+	Records  int      `json:"records"`
+	Problems int      `json:"problems"`
+	Checks   []*Check `json:"checks"`
+}
+
+// Known enum values. Values DF adds in new versions show up as unknown until
+// they're added here.
+var (
+	knownEventTypes = set(
+		"add hf entity honor", "add hf entity link", "add hf hf link", "add hf site link",
+		"agreement formed", "agreement made", "agreement rejected", "artifact claim formed",
+		"artifact copied", "artifact created", "artifact destroyed", "artifact found",
+		"artifact given", "artifact lost", "artifact possessed", "artifact recovered",
+		"artifact stored", "assume identity", "attacked site", "body abused",
+		"building profile acquired", "ceremony", "change creature type", "change hf body state",
+		"change hf job", "change hf state", "competition", "create entity position",
+		"created building", "created site", "created structure", "created world construction",
+		"creature devoured", "dance form created", "destroyed site", "diplomat lost",
+		"entity action", "entity alliance formed", "entity breach feature layer",
+		"entity created", "entity dissolved", "entity equipment purchase", "entity expels hf",
+		"entity fled site", "entity incorporated", "entity law", "entity overthrown",
+		"entity persecuted", "entity primary criminals", "entity rampaged in site",
+		"entity relocate", "entity searched site", "failed frame attempt",
+		"failed intrigue corruption", "field battle", "first contact", "first contact failed",
+		"gamble", "hf abducted", "hf act on artifact", "hf act on building",
+		"hf asked about artifact", "hf attacked site", "hf carouse", "hf confronted",
+		"hf convicted", "hf destroyed site", "hf died", "hf disturbed structure",
+		"hf does interaction", "hf enslaved", "hf equipment purchase", "hf freed",
+		"hf gains secret goal", "hf interrogated", "hf learns secret", "hf new pet",
+		"hf performed horrible experiments", "hf prayed inside structure", "hf preach",
+		"hf profaned structure", "hf ransomed", "hf reach summit",
+		"hf recruited unit type for entity", "hf relationship denied", "hf reunion",
+		"hf revived", "hf simple battle event", "hf sold", "hf spotted leaving site",
+		"hf travel", "hf viewed artifact", "hf wounded", "hfs formed intrigue relationship",
+		"hfs formed reputation relationship", "holy city declaration", "insurrection started",
+		"item stolen", "knowledge discovered", "masterpiece arch constructed",
+		"masterpiece engraving", "masterpiece food", "masterpiece item",
+		"masterpiece item improvement", "masterpiece lost", "merchant", "modified building",
+		"musical form created", "new site leader", "peace accepted", "peace rejected",
+		"performance", "plundered site", "poetic form created", "procession",
+		"razed structure", "reclaim site", "regionpop incorporated into entity",
+		"remove hf entity link", "remove hf hf link", "remove hf site link",
+		"replaced structure", "site died", "site dispute", "site retired",
+		"site surrendered", "site taken over", "site tribute forced", "sneak into site",
+		"spotted leaving site", "squad vs squad", "tactical situation", "trade",
+		"written content composed",
+	)
+	knownStates = set("", "visiting", "settled", "wandering", "refugee", "scouting",
+		"snatcher", "thief", "hunting")
+	knownCollectionTypes = set("war", "battle", "duel", "site conquered", "abduction", "theft",
+		"beast attack", "journey", "insurrection", "occasion", "performance", "competition",
+		"procession", "ceremony", "purge", "entity overthrown", "persecution", "raid")
+	knownEntityLinkTypes = set("member", "former member", "mercenary", "former mercenary",
+		"slave", "former slave", "prisoner", "former prisoner", "enemy", "criminal",
+		"position", "former position", "position claim", "squad", "former squad",
+		"occupation", "former occupation")
+	knownSiteLinkTypes = set("lair", "hangout", "home site building", "home site underground",
+		"home structure", "seat of power", "occupation", "home site realization building",
+		"home site abstract building", "prison site building profile",
+		"prison site realization building")
+)
+
+func set(values ...string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+// validator collects problems into checks.
+type validator struct {
+	checks map[[3]string]*Check
+}
+
+func (v *validator) add(kind, section, field, record, format string, args ...interface{}) {
+	key := [3]string{kind, section, field}
+	c := v.checks[key]
+	if c == nil {
+		c = &Check{Kind: kind, Section: section, Field: field}
+		v.checks[key] = c
+	}
+	c.Count++
+	if len(c.Examples) < maxExamples {
+		c.Examples = append(c.Examples, &Problem{Record: record, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// ref adds a dangling reference problem if id is set but not found.
+func (v *validator) ref(section, field, record string, id int, found bool) {
+	if id != -1 && !found {
+		v.add(ProblemDangling, section, field, record, "%s %d not found", field, id)
+	}
+}
+
+// unknown adds an unknown value problem if value isn't known.
+func (v *validator) unknown(section, field, record, value string, known map[string]bool) {
+	if !known[value] {
+		v.add(ProblemUnknown, section, field, record, "%s %q", field, value)
+	}
+}
+
+// duplicates adds duplicate id problems for ids of a section.
+func (v *validator) duplicates(section string, ids []int) {
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			v.add(ProblemDuplicate, section, "id", fmt.Sprintf("%s %d", section, id), "id %d used more than once", id)
+		}
+		seen[id] = true
+	}
+}
+
+// Validate checks every cross reference between records, duplicate IDs,
+// impossible dates and unknown enum values.
+func (w *World) Validate() *Report {
+	v := &validator{checks: map[[3]string]*Check{}}
+	r := &Report{}
+
+	idsOf := func(n int, id func(int) int) []int {
+		r.Records += n
+		ids := make([]int, n)
+		for i := range ids {
+			ids[i] = id(i)
+		}
+		return ids
+	}
+	v.duplicates("region", idsOf(len(w.Regions), func(i int) int { return w.Regions[i].ID }))
+	v.duplicates("underground region", idsOf(len(w.UndergroundRegions), func(i int) int { return w.UndergroundRegions[i].ID }))
+	v.duplicates("site", idsOf(len(w.Sites), func(i int) int { return w.Sites[i].ID }))
+	v.duplicates("artifact", idsOf(len(w.Artifacts), func(i int) int { return w.Artifacts[i].ID }))
+	v.duplicates("figure", idsOf(len(w.Figures), func(i int) int { return w.Figures[i].ID }))
+	v.duplicates("entity", idsOf(len(w.Entities), func(i int) int { return w.Entities[i].ID }))
+	v.duplicates("event", idsOf(len(w.Events), func(i int) int { return w.Events[i].ID }))
+	v.duplicates("collection", idsOf(len(w.Collections), func(i int) int { return w.Collections[i].ID }))
+	v.duplicates("written content", idsOf(len(w.WrittenContents), func(i int) int { return w.WrittenContents[i].ID }))
+	v.duplicates("poetic form", idsOf(len(w.PoeticForms), func(i int) int { return w.PoeticForms[i].ID }))
+	v.duplicates("musical form", idsOf(len(w.MusicalForms), func(i int) int { return w.MusicalForms[i].ID }))
+	v.duplicates("dance form", idsOf(len(w.DanceForms), func(i int) int { return w.DanceForms[i].ID }))
+
+	regions := map[int]bool{}
+	for _, reg := range w.Regions {
+		regions[reg.ID] = true
+	}
+	underground := map[int]bool{}
+	for _, reg := range w.UndergroundRegions {
+		underground[reg.ID] = true
+	}
+	artifacts := map[int]bool{}
+	for _, a := range w.Artifacts {
+		artifacts[a.ID] = true
+	}
+
+	for _, s := range w.Sites {
+		rec := fmt.Sprintf("site %d", s.ID)
+		v.ref("site", "civ_id", rec, s.CivID, w.Entity(s.CivID) != nil)
+		v.ref("site", "cur_owner_id", rec, s.CurOwnerID, w.Entity(s.CurOwnerID) != nil)
+		// Structure IDs are only unique within their site
+		seen := make(map[int]bool, len(s.Structures))
+		for _, st := range s.Structures {
+			srec := fmt.Sprintf("site %d structure %d", s.ID, st.ID)
+			v.ref("structure", "entity_id", srec, st.EntityID, w.Entity(st.EntityID) != nil)
+			v.ref("structure", "worship_hfid", srec, st.WorshipFigureID, w.Figure(st.WorshipFigureID) != nil)
+			if seen[st.ID] {
+				v.add(ProblemDuplicate, "structure", "id", srec, "id %d used more than once in site %d", st.ID, s.ID)
+			}
+			seen[st.ID] = true
+		}
+		r.Records += len(s.Structures)
+	}
+
+	for _, f := range w.Figures {
+		rec := fmt.Sprintf("figure %d", f.ID)
+		if f.BirthYear != -1 && f.DeathYear != -1 && f.DeathYear < f.BirthYear {
+			v.add(ProblemDate, "figure", "death_year", rec, "died in %d before being born in %d", f.DeathYear, f.BirthYear)
+		}
+		for _, l := range f.Entities {
+			v.ref("figure", "entity_link", rec, l.ID, w.Entity(l.ID) != nil)
+			v.unknown("figure", "entity_link.link_type", rec, l.Type, knownEntityLinkTypes)
+		}
+		for _, l := range f.Links {
+			v.ref("figure", "hf_link", rec, l.ID, w.Figure(l.ID) != nil)
+		}
+		for _, l := range f.Sites {
+			v.ref("figure", "site_link", rec, l.ID, w.Site(l.ID) != nil)
+			v.unknown("figure", "site_link.link_type", rec, l.Type, knownSiteLinkTypes)
+		}
+	}
+
+	for _, e := range w.Events {
+		rec := fmt.Sprintf("event %d", e.ID)
+		v.unknown("event", "type", rec, e.Type, knownEventTypes)
+		v.unknown("event", "state", rec, e.State, knownStates)
+		v.ref("event", "hfid", rec, e.FigureID, w.Figure(e.FigureID) != nil)
+		v.ref("event", "slayer_hfid", rec, e.SlayerFigureID, w.Figure(e.SlayerFigureID) != nil)
+		v.ref("event", "hist_figure_id", rec, e.HistFigureID, w.Figure(e.HistFigureID) != nil)
+		v.ref("event", "doer_hfid", rec, e.DoerFigureID, w.Figure(e.DoerFigureID) != nil)
+		v.ref("event", "target_hfid", rec, e.TargetFigureID, w.Figure(e.TargetFigureID) != nil)
+		v.ref("event", "snatcher_hfid", rec, e.SnatcherFigureID, w.Figure(e.SnatcherFigureID) != nil)
+		v.ref("event", "woundee_hfid", rec, e.WoundeeFigureID, w.Figure(e.WoundeeFigureID) != nil)
+		v.ref("event", "wounder_hfid", rec, e.WounderFigureID, w.Figure(e.WounderFigureID) != nil)
+		v.ref("event", "group_1_hfid", rec, e.Group1FigureID, w.Figure(e.Group1FigureID) != nil)
+		v.ref("event", "group_2_hfid", rec, e.Group2FigureID, w.Figure(e.Group2FigureID) != nil)
+		v.ref("event", "hfid_target", rec, e.HFTargetID, w.Figure(e.HFTargetID) != nil)
+		v.ref("event", "attacker_hfid", rec, e.AttackerFigureID, w.Figure(e.AttackerFigureID) != nil)
+		v.ref("event", "defender_hfid", rec, e.DefenderFigureID, w.Figure(e.DefenderFigureID) != nil)
+		v.ref("event", "group_hfid", rec, e.GroupFigureID, w.Figure(e.GroupFigureID) != nil)
+		v.ref("event", "trickster_hfid", rec, e.TricksterFigureID, w.Figure(e.TricksterFigureID) != nil)
+		v.ref("event", "artifact_id", rec, e.ArtifactID, w.Artifact(e.ArtifactID) != nil)
+		v.ref("event", "site_id", rec, e.SiteID, w.Site(e.SiteID) != nil)
+		v.ref("event", "subregion_id", rec, e.SubregionID, regions[e.SubregionID])
+		v.ref("event", "feature_layer_id", rec, e.FeatureLayerID, underground[e.FeatureLayerID])
+		v.ref("event", "attacker_civ_id", rec, e.AttackerCivID, w.Entity(e.AttackerCivID) != nil)
+		v.ref("event", "defender_civ_id", rec, e.DefenderCivID, w.Entity(e.DefenderCivID) != nil)
+		v.ref("event", "civ_id", rec, e.CivID, w.Entity(e.CivID) != nil)
+		v.ref("event", "new_site_civ_id", rec, e.NewSiteCivID, w.Entity(e.NewSiteCivID) != nil)
+		v.ref("event", "site_civ_id", rec, e.SiteCivID, w.Entity(e.SiteCivID) != nil)
+		v.ref("event", "target_enid", rec, e.TargetEntityID, w.Entity(e.TargetEntityID) != nil)
+		v.ref("event", "entity_id", rec, e.EntityID, w.Entity(e.EntityID) != nil)
+		if f := w.Figure(e.FigureID); f != nil && e.Type == "change hf state" && f.DiedIn != -1 && e.Year > f.DiedIn {
+			v.add(ProblemDate, "event", "year", rec, "%s in %d after hf %d died in %d", e.Type, e.Year, f.ID, f.DiedIn)
+		}
+	}
+
+	for _, c := range w.Collections {
+		rec := fmt.Sprintf("collection %d", c.ID)
+		v.unknown("collection", "type", rec, c.Type, knownCollectionTypes)
+		if c.EndYear != -1 && c.EndYear < c.StartYear {
+			v.add(ProblemDate, "collection", "end_year", rec, "ended in %d before starting in %d", c.EndYear, c.StartYear)
+		}
+		for _, id := range c.EventIDs {
+			v.ref("collection", "event", rec, id, w.Event(id) != nil)
+		}
+		for _, id := range c.CollectionIDs {
+			v.ref("collection", "eventcol", rec, id, w.Collection(id) != nil)
+		}
+		v.ref("collection", "war_eventcol", rec, c.WarID, w.Collection(c.WarID) != nil)
+		v.ref("collection", "aggressor_ent_id", rec, c.AggressorEntityID, w.Entity(c.AggressorEntityID) != nil)
+		v.ref("collection", "defender_ent_id", rec, c.DefenderEntityID, w.Entity(c.DefenderEntityID) != nil)
+		v.ref("collection", "site_id", rec, c.SiteID, w.Site(c.SiteID) != nil)
+		for _, id := range c.AttackingFigureIDs {
+			v.ref("collection", "attacking_hfid", rec, id, w.Figure(id) != nil)
+		}
+		for _, id := range c.DefendingFigureIDs {
+			v.ref("collection", "defending_hfid", rec, id, w.Figure(id) != nil)
+		}
+	}
+
+	for _, c := range w.WrittenContents {
+		rec := fmt.Sprintf("written content %d", c.ID)
+		v.ref("written content", "author_hfid", rec, c.AuthorFigureID, w.Figure(c.AuthorFigureID) != nil)
+		switch c.Form {
+		case "poem", "musical composition", "choreography":
+			v.ref("written content", "form_id", rec, c.FormID, w.WrittenContentForm(c) != nil)
+		}
+		for _, ref := range c.References {
+			var found bool
+			switch ref.Type {
+			case RefFigure:
+				found = w.Figure(ref.ID) != nil
+			case RefSite:
+				found = w.Site(ref.ID) != nil
+			case RefEntity:
+				found = w.Entity(ref.ID) != nil
+			case RefArtifact:
+				found = artifacts[ref.ID]
+			case RefEvent:
+				found = w.Event(ref.ID) != nil
+			case RefWrittenContent:
+				found = w.WrittenContent(ref.ID) != nil
+			case RefPoeticForm:
+				found = w.PoeticForm(ref.ID) != nil
+			case RefMusicalForm:
+				found = w.MusicalForm(ref.ID) != nil
+			case RefDanceForm:
+				found = w.DanceForm(ref.ID) != nil
+			default:
+				continue
+			}
+			v.ref("written content", "reference "+ref.Type, rec, ref.ID, found)
+		}
+	}
+
+	for _, c := range v.checks {
+		r.Problems += c.Count
+		r.Checks = append(r.Checks, c)
+	}
+	sort.Slice(r.Checks, func(i, j int) bool {
+		a, b := r.Checks[i], r.Checks[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Section != b.Section {
+			return a.Section < b.Section
+		}
+		return a.Field < b.Field
+	})
+	return r
+}
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"golang.org/x/text/encoding/charmap"
+
+	"github.com/schmichael/legendarygopher/lg"
+	"github.com/schmichael/legendarygopher/web"
+)
+
+// Magic bytes of the compression and archive formats we sniff
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	zipMagic   = []byte("PK\x03\x04")
+	sevenMagic = []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}
+)
+
+// lenient keeps what was decoded from invalid legends xml instead of exiting.
+var lenient bool
+
+// exportFiles are the files of a legends export: the legends files to load
+// (legends.xml first), the plain text exports, the world map images and every
+// file in the archives they came from.
+type exportFiles struct {
+	legends []*web.File
+	texts   []*web.File
+	maps    []*web.File
+	all     []*web.File
+
+	// archives are kept open until the files are closed
+	archives []io.Closer
+}
+
+// Close closes the archives the files are in.
+func (e *exportFiles) Close() error {
+	var first error
+	for _, a := range e.archives {
+		if err := a.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	e.archives = nil
+	return first
+}
+
+// load the legends file in args[0] and any supplemental files or exit.
+func load(args []string) *lg.World {
+	world, _ := loadExport(args, newProgress())
+	return world
+}
+
+// loadExport is load but also returns the files of the export and reports
+// progress to p.
+func loadExport(args []string, p *progress) (*lg.World, *exportFiles) {
+	world, files, err := loadFiles(args, p)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(err.code)
+	}
+	return world, files
+}
+
+// loadError is an error loading a world and the exit code for it.
+type loadError struct {
+	code int
+	err  error
+}
+
+func (e *loadError) Error() string { return e.err.Error() }
+
+// loadFiles is loadExport but returns errors instead of exiting.
+func loadFiles(args []string, p *progress) (*lg.World, *exportFiles, *loadError) {
+	stop := p.report(os.Stderr)
+	fail := func(code int, format string, args ...interface{}) (*lg.World, *exportFiles, *loadError) {
+		stop()
+		err := &loadError{code, fmt.Errorf(format, args...)}
+		p.finish(err)
+		return nil, nil, err
+	}
+
+	files, err := sources(args)
+	if err != nil {
+		return fail(exitIO, "%v", err)
+	}
+	fail = func(code int, format string, args ...interface{}) (*lg.World, *exportFiles, *loadError) {
+		files.Close()
+		stop()
+		err := &loadError{code, fmt.Errorf(format, args...)}
+		p.finish(err)
+		return nil, nil, err
+	}
+	srcs := files.legends
+
+	f, err := srcs[0].Open()
+	if err != nil {
+		return fail(exitIO, "unable to open file %q: %v", srcs[0].Name, err)
+	}
+
+	p.begin(srcs[0].Name, srcs[0].Size)
+	dec, rc, err := decoder(p.reader(f), p)
+	if err != nil {
+		f.Close()
+		return fail(exitIO, "error reading %q: %v", srcs[0].Name, err)
+	}
+
+	// Let's see how much memory it takes
+	m := runtime.MemStats{}
+	runtime.ReadMemStats(&m)
+	alloc := m.Alloc
+
+	start := time.Now()
+	world, err := lg.New(dec)
+	rc.Close()
+	if err != nil {
+		return fail(exitParse, "error reading legends file %q: %v", srcs[0].Name, err)
+	}
+	warnLenient(p, srcs[0], dec)
+
+	// Merge supplemental exports like legends_plus.xml
+	for _, src := range srcs[1:] {
+		if err := merge(world, src, p); err != nil {
+			return fail(exitParse, "error reading legends file %q: %v", src.Name, err)
+		}
+	}
+	for _, src := range files.texts {
+		if err := mergeText(world, src, p); err != nil {
+			return fail(exitParse, "error reading %q: %v", src.Name, err)
+		}
+	}
+	dur := time.Now().Sub(start)
+	stop()
+	p.finish(nil)
+
+	runtime.ReadMemStats(&m)
+	size := int64(math.Max(0, float64(srcs[0].Size)))
+	fmt.Fprintf(os.Stderr, "took %s (%d KBps) and approximately %d MB of memory\n",
+		dur, (size/1024)/int64(math.Max(1, float64(dur/time.Second))), (m.Alloc-alloc)/1024/1024)
+	return world, files, nil
+}
+
+// sources returns the files to load for the command line arguments: the
+// legends file first followed by supplemental files. Archives are expanded
+// into the legends files they contain.
+func sources(args []string) (*exportFiles, error) {
+	files := &exportFiles{}
+	for _, arg := range args {
+		f, err := fileSources(arg)
+		if err != nil {
+			files.Close()
+			return nil, err
+		}
+		files.add(f)
+	}
+	if len(files.legends) == 0 {
+		files.Close()
+		return nil, fmt.Errorf("no legends files in %s", strings.Join(args, ", "))
+	}
+	if len(files.legends) == 1 {
+		if fn := plusFile(files.legends[0].Name); fn != "" {
+			f, err := fileSources(fn)
+			if err != nil {
+				files.Close()
+				return nil, err
+			}
+			// Its siblings are the same as the legends.xml's
+			files.legends = append(files.legends, f.legends...)
+			files.archives = append(files.archives, f.archives...)
+		}
+	}
+	return files, nil
+}
+
+// add the files of o, skipping legends, texts and maps e already has: every
+// legends file in a directory, e.g. legends.xml and legends_plus.xml, has
+// the same siblings.
+func (e *exportFiles) add(o *exportFiles) {
+	e.legends = appendNew(e.legends, o.legends)
+	e.texts = appendNew(e.texts, o.texts)
+	e.maps = appendNew(e.maps, o.maps)
+	e.all = append(e.all, o.all...)
+	e.archives = append(e.archives, o.archives...)
+}
+
+// appendNew appends the files in add not named like a file in files.
+func appendNew(files, add []*web.File) []*web.File {
+	for _, f := range add {
+		if !slices.ContainsFunc(files, func(o *web.File) bool { return o.Name == f.Name }) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// fileSources returns the source for a path or "-" (stdin), or the files
+// inside it if it's a zip or 7z archive.
+func fileSources(fn string) (*exportFiles, error) {
+	if fn == "-" {
+		br := bufio.NewReader(os.Stdin)
+		magic, _ := br.Peek(len(sevenMagic))
+		if bytes.HasPrefix(magic, zipMagic) || bytes.HasPrefix(magic, sevenMagic) {
+			// archives need random access so read the whole archive
+			buf, err := ioutil.ReadAll(br)
+			if err != nil {
+				return nil, fmt.Errorf("error reading stdin: %v", err)
+			}
+			return archiveSources(fn, bytes.NewReader(buf), int64(len(buf)), magic)
+		}
+		src := &web.File{Name: fn, Size: -1, Open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(br), nil
+		}}
+		return &exportFiles{legends: []*web.File{src}}, nil
+	}
+
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %q: %v", fn, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error getting file size: %v", err)
+	}
+	magic := make([]byte, len(sevenMagic))
+	n, _ := io.ReadFull(f, magic)
+	magic = magic[:n]
+	if bytes.HasPrefix(magic, zipMagic) || bytes.HasPrefix(magic, sevenMagic) {
+		// Keep the archive open until the files are closed
+		files, err := archiveSources(fn, f, fi.Size(), magic)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		files.archives = []io.Closer{f}
+		return files, nil
+	}
+	f.Close()
+	src := &web.File{Name: fn, Size: fi.Size(), Open: func() (io.ReadCloser, error) {
+		return os.Open(fn)
+	}}
+	if isText(fn) {
+		return &exportFiles{texts: []*web.File{src}}, nil
+	}
+	files := siblingFiles(fn)
+	files.legends = []*web.File{src}
+	return files, nil
+}
+
+// archiveSources returns the legends.xml and legends_plus.xml files, map
+// images and all other files in a zip or 7z archive.
+func archiveSources(fn string, r io.ReaderAt, size int64, magic []byte) (*exportFiles, error) {
+	files := &exportFiles{}
+	if bytes.HasPrefix(magic, zipMagic) {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return nil, fmt.Errorf("error reading archive %q: %v", fn, err)
+		}
+		for _, zf := range zr.File {
+			if !zf.FileInfo().IsDir() {
+				files.all = append(files.all, &web.File{Name: zf.Name, Size: int64(zf.UncompressedSize64), Open: zf.Open})
+			}
+		}
+	} else {
+		sr, err := sevenzip.NewReader(r, size)
+		if err != nil {
+			return nil, fmt.Errorf("error reading archive %q: %v", fn, err)
+		}
+		for _, sf := range sr.File {
+			if !sf.FileInfo().IsDir() {
+				files.all = append(files.all, &web.File{Name: sf.Name, Size: int64(sf.UncompressedSize), Open: sf.Open})
+			}
+		}
+	}
+
+	var legends, plus []*web.File
+	for _, f := range files.all {
+		name := strings.ToLower(path.Base(f.Name))
+		switch {
+		case strings.Contains(name, "legends_plus"):
+			plus = append(plus, &web.File{Name: fn + ":" + f.Name, Size: f.Size, Open: f.Open})
+		case strings.Contains(name, "legends"):
+			legends = append(legends, &web.File{Name: fn + ":" + f.Name, Size: f.Size, Open: f.Open})
+		case isText(name):
+			files.texts = append(files.texts, f)
+		case isMap(name):
+			files.maps = append(files.maps, f)
+		}
+	}
+	if len(legends) == 0 {
+		return nil, fmt.Errorf("no legends files in archive %q", fn)
+	}
+	files.legends = append(legends, plus...)
+	return files, nil
+}
+
+// siblingFiles returns the plain text exports and map images DF exported
+// next to a legends.xml.
+func siblingFiles(fn string) *exportFiles {
+	files := &exportFiles{}
+	i := strings.Index(fn, "-legends")
+	if i == -1 {
+		return files
+	}
+	matches, _ := filepath.Glob(fn[:i] + "-*")
+	for _, m := range matches {
+		fi, err := os.Stat(m)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		m := m
+		src := &web.File{Name: filepath.Base(m), Size: fi.Size(), Open: func() (io.ReadCloser, error) {
+			return os.Open(m)
+		}}
+		switch {
+		case isText(m):
+			src.Name = m
+			files.texts = append(files.texts, src)
+		case isMap(m):
+			files.maps = append(files.maps, src)
+		}
+	}
+	return files
+}
+
+// isText returns true if fn is one of the plain text exports we parse.
+func isText(fn string) bool {
+	fn = strings.ToLower(path.Base(fn))
+	return strings.Contains(fn, "world_history") || strings.Contains(fn, "world_sites_and_pops")
+}
+
+// isMap returns true if fn is an image.
+func isMap(fn string) bool {
+	switch strings.ToLower(path.Ext(fn)) {
+	case ".bmp", ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	}
+	return false
+}
+
+// plusFile returns the legends_plus.xml DFHack exports next to the
+// legends.xml or "" if there isn't one.
+func plusFile(fn string) string {
+	if !strings.Contains(fn, "-legends.xml") {
+		return ""
+	}
+	fn = strings.Replace(fn, "-legends.xml", "-legends_plus.xml", 1)
+	if _, err := os.Stat(fn); err != nil {
+		return ""
+	}
+	return fn
+}
+
+// decoder sniffs the content of rc, wrapping it in decompressors until it
+// finds the format of the legends data and returns a decoder for it.
+func decoder(rc io.ReadCloser, p *progress) (lg.Decoder, io.ReadCloser, error) {
+	br, rc, err := decompress(rc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if magic, _ := br.Peek(len(lg.SnapshotMagic)); bytes.Equal(magic, []byte(lg.SnapshotMagic)) {
+		dec, err := lg.NewSnapshotDecoder(br)
+		return dec, rc, err
+	}
+	switch firstByte(br) {
+	case '<':
+		rd := lg.NewXMLDecoder(br)
+		rd.Lenient = lenient
+		rd.OnRecord = p.record
+		return rd, rc, nil
+	case '{':
+		return json.NewDecoder(br), rc, nil
+	}
+	magic, _ := br.Peek(len(lg.SnapshotMagic))
+	return nil, nil, fmt.Errorf("unknown format (starts with %q)", magic)
+}
+
+// decompress sniffs the content of rc and wraps it in decompressors until
+// it's uncompressed.
+func decompress(rc io.ReadCloser) (*bufio.Reader, io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	for {
+		magic, err := br.Peek(len(lg.SnapshotMagic))
+		if err != nil && len(magic) == 0 {
+			return nil, nil, fmt.Errorf("unable to detect format: %v", err)
+		}
+
+		switch {
+		case bytes.HasPrefix(magic, gzipMagic):
+			gz, err := gzip.NewReader(br)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error decompressing gzip: %v", err)
+			}
+			rc = &closer{gz, rc}
+
+		case bytes.HasPrefix(magic, bzip2Magic):
+			rc = &closer{bzip2.NewReader(br), rc}
+
+		case bytes.HasPrefix(magic, xzMagic):
+			xr, err := xz.NewReader(br)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error decompressing xz: %v", err)
+			}
+			rc = &closer{xr, rc}
+
+		case bytes.HasPrefix(magic, zstdMagic):
+			zr, err := zstd.NewReader(br)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error decompressing zstd: %v", err)
+			}
+			rc = &closer{zr.IOReadCloser(), rc}
+
+		default:
+			return br, rc, nil
+		}
+		br = bufio.NewReader(rc)
+	}
+}
+
+// firstByte returns the first byte in br which isn't whitespace or a UTF-8
+// byte order mark.
+func firstByte(br *bufio.Reader) byte {
+	for i := 1; ; i++ {
+		buf, err := br.Peek(i)
+		if len(buf) < i {
+			return 0
+		}
+		switch c := buf[i-1]; c {
+		case ' ', '\t', '\r', '\n', 0xef, 0xbb, 0xbf:
+		default:
+			return c
+		}
+		if err != nil || i > 4096 {
+			return 0
+		}
+	}
+}
+
+func merge(w *lg.World, src *web.File, p *progress) error {
+	f, err := src.Open()
+	if err != nil {
+		return err
+	}
+	p.begin(src.Name, src.Size)
+	dec, rc, err := decoder(p.reader(f), p)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	defer rc.Close()
+	p.logf("merging %s\n", src.Name)
+	if err := w.Merge(dec); err != nil {
+		return err
+	}
+	warnLenient(p, src, dec)
+	return nil
+}
+
+// warnLenient prints where a lenient decoder stopped decoding, if it did.
+func warnLenient(p *progress, src *web.File, dec lg.Decoder) {
+	if rd, ok := dec.(*lg.RecordDecoder); ok && rd.Err() != nil {
+		p.logf("warning: %s: %v\n", src.Name, rd.Err())
+	}
+}
+
+// mergeText merges a world_history.txt or world_sites_and_pops.txt export.
+func mergeText(w *lg.World, src *web.File, p *progress) error {
+	f, err := src.Open()
+	if err != nil {
+		return err
+	}
+	p.begin(src.Name, src.Size)
+	br, rc, err := decompress(p.reader(f))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	defer rc.Close()
+	p.logf("merging %s\n", src.Name)
+	r := charmap.CodePage437.NewDecoder().Reader(br)
+	if strings.Contains(strings.ToLower(path.Base(src.Name)), "world_history") {
+		return w.MergeHistory(r)
+	}
+	return w.MergeSitesAndPops(r)
+}
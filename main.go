@@ -1,109 +1,216 @@
 package main
 
 import (
-	"compress/bzip2"
-	"compress/gzip"
-	"encoding/json"
-	"encoding/xml"
 	"flag"
 	"fmt"
-	"math"
+	"net/http"
 	"os"
-	"runtime"
-	"strings"
 	"time"
 
-	"golang.org/x/text/encoding/charmap"
+	"github.com/schmichael/legendarygopher/web"
+)
 
-	"github.com/schmichael/legendarygopher/lg"
+// Exit codes
+const (
+	exitUsage   = 10 // bad command line
+	exitIO      = 11 // unable to open, decompress or write a file
+	exitParse   = 12 // unable to parse a legends file
+	exitInvalid = 13 // validate found problems
+	exitDiff    = 14 // diff found differences
+	exitNoMatch = 15 // query matched nothing
 )
 
+type command struct {
+	name string
+	args string
+	help string
+	run  func(args []string)
+}
+
+var commands []*command
+
+func init() {
+	// Initialized here to avoid an initialization loop with usage
+	commands = []*command{
+		{"serve", "[flags] dump.xml [legends_plus.xml]", "start the web viewer", serveMain},
+		{"dump", "[flags] dump.xml [legends_plus.xml]", "print world records as text", dumpMain},
+		{"export", "chronicle|snapshot [flags] dump.xml [legends_plus.xml]", "export the world as a book or snapshot", exportMain},
+		{"stats", "dump.xml [legends_plus.xml]", "print world statistics", statsMain},
+		{"validate", "[flags] dump.xml [legends_plus.xml]", "check references, ids, dates and values", validateMain},
+		{"diff", "[flags] old.xml new.xml", "compare two worlds", diffMain},
+		{"query", "[flags] dump.xml [legends_plus.xml]", "search world records", queryMain},
+	}
+}
+
 func main() {
-	bind := "localhost:6565"
-	flag.StringVar(&bind, "http", bind, "start web server")
-	flag.Parse()
-	if len(flag.Args()) < 1 {
+	if len(os.Args) < 2 {
 		usageExit()
 	}
-
-	f, err := os.Open(flag.Arg(0))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "unable to open file %q: %v\n", flag.Arg(0), err)
-		os.Exit(11)
+	switch os.Args[1] {
+	case "help", "-h", "-help", "--help":
+		if len(os.Args) > 2 {
+			if c := findCommand(os.Args[2]); c != nil {
+				c.run([]string{"-h"})
+			}
+		}
+		usage()
+		os.Exit(0)
+	}
+	if c := findCommand(os.Args[1]); c != nil {
+		c.run(os.Args[2:])
+		return
 	}
 
-	fi, err := f.Stat()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error getting file size: %v\n", err)
-		os.Exit(11)
+	// No command: legendarygopher [-http=addr] dump.xml
+	legacyMain()
+}
+
+// legacyMain handles the original command line: serve by default or dump
+// with -http="".
+func legacyMain() {
+	flag.BoolVar(&lenient, "lenient", false, "keep what was decoded from invalid legends xml")
+	opts := serveFlags(flag.CommandLine)
+	flag.Usage = usage
+	flag.Parse()
+	if len(flag.Args()) < 1 {
+		usageExit()
 	}
 
-	rc := progger(f, fi.Size())
+	if opts.bind == "" {
+		// Don't start web server; just dump and exit
+		world := load(flag.Args())
+		if err := (&dumper{format: "table"}).dump(os.Stdout, world); err != nil {
+			fmt.Fprintf(os.Stderr, "error dumping: %v\n", err)
+			os.Exit(exitIO)
+		}
+		return
+	}
+	serve(flag.Args(), *opts)
+}
 
-	fnparts := strings.Split(flag.Arg(0), ".")
-	var dec lg.Decoder
+type serveOptions struct {
+	bind         string
+	prefix       string
+	templates    string
+	dev          bool
+	watch        bool
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
 
-	// Wrap readers until we find a decoder
-	for len(fnparts) > 0 && dec == nil {
-		switch fnparts[len(fnparts)-1] {
-		case "gz":
-			if rc, err = gzip.NewReader(rc); err != nil {
-				fmt.Fprintf(os.Stderr, "error decompressing %q: %v\n", flag.Arg(0), err)
-				os.Exit(11)
-			}
-			// pop .gz extension and continue
-			fnparts = fnparts[:len(fnparts)-1]
+func serveMain(args []string) {
+	fs := newFlagSet("serve")
+	opts := serveFlags(fs)
+	parseFlags(fs, args, 1)
 
-		case "bz2":
-			rc = &closer{bzip2.NewReader(rc), rc}
-			// pop .bz2 extension and continue
-			fnparts = fnparts[:len(fnparts)-1]
+	serve(fs.Args(), *opts)
+}
 
-		case "xml":
-			// Convert from cp437 to utf8 and decode xml
-			dec = xml.NewDecoder(charmap.CodePage437.NewDecoder().Reader(rc))
+// serveFlags defines the flags of serve, which the bare form shares, on fs.
+func serveFlags(fs *flag.FlagSet) *serveOptions {
+	var opts serveOptions
+	fs.StringVar(&opts.bind, "http", "localhost:6565", "address to listen on")
+	fs.StringVar(&opts.prefix, "prefix", "", "serve under this path, e.g. /legends")
+	fs.StringVar(&opts.templates, "templates", "", "load templates and css from this directory, falling back to the built in ones")
+	fs.BoolVar(&opts.dev, "dev", false, "reload templates on every request")
+	fs.BoolVar(&opts.watch, "watch", false, "reload when the legends file or a newer export next to it changes")
+	fs.DurationVar(&opts.readTimeout, "read-timeout", 30*time.Second, "maximum duration for reading a request")
+	fs.DurationVar(&opts.writeTimeout, "write-timeout", 10*time.Minute, "maximum duration for writing a response (0 for none)")
+	return &opts
+}
 
-		case "json":
-			dec = json.NewDecoder(rc)
+// serve starts the web server immediately and loads the world in the
+// background, reloading it when it changes if watching.
+func serve(args []string, opts serveOptions) {
+	p := newProgress()
+	wopts := web.Options{Prefix: opts.prefix, Dev: opts.dev}
+	if opts.templates != "" {
+		if fi, err := os.Stat(opts.templates); err != nil || !fi.IsDir() {
+			fmt.Fprintf(os.Stderr, "templates must be a directory: %q\n", opts.templates)
+			os.Exit(exitUsage)
 		}
+		wopts.Templates = os.DirFS(opts.templates)
 	}
-
-	if dec == nil {
-		fmt.Fprintf(os.Stderr, "unknown extension %q in %q\n", fnparts[len(fnparts)-1])
-		os.Exit(11)
+	s, err := web.NewLoading(p, wopts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error starting web server: %v\n", err)
+		os.Exit(exitUsage)
 	}
+	go func() {
+		world, files := loadExport(args, p)
+		s.SetWorld(&web.Loaded{World: world, Files: files.all, Maps: files.maps, Closer: files})
+		if opts.watch {
+			watch(s, args, 2*time.Second)
+		}
+	}()
+	fmt.Printf("Open http://%s%s\n", opts.bind, opts.prefix)
+	runserver(&http.Server{
+		Addr:         opts.bind,
+		Handler:      logRequests(s),
+		ReadTimeout:  opts.readTimeout,
+		WriteTimeout: opts.writeTimeout,
+		IdleTimeout:  2 * time.Minute,
+	})
+}
 
-	// Let's see how much memory it takes
-	m := runtime.MemStats{}
-	runtime.ReadMemStats(&m)
-	alloc := m.Alloc
-
-	start := time.Now()
-	world, err := lg.New(dec)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error reading legends file %q: %v\n", flag.Arg(0), err)
-		os.Exit(12)
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
 	}
-	dur := time.Now().Sub(start)
-	rc.Close()
-	f.Close()
+	return nil
+}
 
-	runtime.ReadMemStats(&m)
-	fmt.Fprintf(os.Stderr, "took %s (%d KBps) and approximately %d MB of memory\n",
-		dur, (fi.Size()/1024)/int64(math.Max(1, float64(dur/time.Second))), (m.Alloc-alloc)/1024/1024)
+// newFlagSet returns a FlagSet for a command which prints the command's
+// usage.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&lenient, "lenient", false, "keep what was decoded from invalid legends xml")
+	c := findCommand(name)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s %s %s\n\n%s\n\n", os.Args[0], c.name, c.args, c.help)
+		fs.PrintDefaults()
+	}
+	return fs
+}
 
-	if bind == "" {
-		// Don't start web server; just exit
-		fmt.Println(world)
-		return
+// parseFlags parses a command's flags and exits if they're invalid or fewer
+// than min arguments are given.
+func parseFlags(fs *flag.FlagSet, args []string, min int) {
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		os.Exit(0)
+	} else if err != nil {
+		os.Exit(exitUsage)
+	}
+	if fs.NArg() < min {
+		fs.Usage()
+		os.Exit(exitUsage)
 	}
+}
 
-	fmt.Printf("Open http://%s\n", bind)
-	runserver(bind, world)
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [flags] dump.xml [legends_plus.xml]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "dump.xml may be legends xml, json or a snapshot; compressed with gzip, bzip2,\n")
+	fmt.Fprintf(os.Stderr, "xz or zstd; in a zip archive; or \"-\" for stdin.\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", c.name, c.help)
+	}
+	fmt.Fprintf(os.Stderr, `
+Run "%s help <command>" for a command's flags. Without a command the web
+viewer is started (or the world dumped with -http="").
+
+exit codes:
+  %d  incorrect usage
+  %d  unable to open, decompress or write a file
+  %d  unable to parse a legends file
+  %d  validate found problems
+  %d  diff found differences
+  %d  query matched nothing
+`, os.Args[0], exitUsage, exitIO, exitParse, exitInvalid, exitDiff, exitNoMatch)
 }
 
 func usageExit() {
-	fmt.Fprintf(os.Stderr, "incorrect usage, expected: %s dump.xml\n", os.Args[0])
-	flag.PrintDefaults()
-	os.Exit(10)
+	usage()
+	os.Exit(exitUsage)
 }
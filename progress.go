@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schmichael/legendarygopher/web"
+)
+
+// progress of loading a world. The loader updates it while the web server
+// and the command line progress bar observe it.
+type progress struct {
+	mu        sync.Mutex
+	file      string
+	read      int64
+	size      int64
+	sections  map[string]int
+	start     time.Time
+	fileStart time.Time
+	ready     bool
+	err       string
+
+	// changed is closed and replaced when progress is made
+	changed  chan struct{}
+	notified time.Time
+
+	// tty is true while a progress bar is drawn on stderr
+	tty bool
+}
+
+func newProgress() *progress {
+	now := time.Now()
+	return &progress{
+		size:      -1,
+		sections:  map[string]int{},
+		start:     now,
+		fileStart: now,
+		changed:   make(chan struct{}),
+	}
+}
+
+// begin reading file of size bytes (-1 if unknown).
+func (p *progress) begin(file string, size int64) {
+	p.mu.Lock()
+	p.file, p.size, p.read, p.fileStart = file, size, 0, time.Now()
+	p.notify(true)
+	p.mu.Unlock()
+}
+
+// record counts a record decoded from an xml section.
+func (p *progress) record(section string) {
+	p.mu.Lock()
+	p.sections[section]++
+	p.notify(false)
+	p.mu.Unlock()
+}
+
+// finish loading with an error or nil if the world is ready.
+func (p *progress) finish(err error) {
+	p.mu.Lock()
+	if err != nil {
+		p.err = err.Error()
+	} else {
+		p.ready = true
+	}
+	p.notify(true)
+	p.mu.Unlock()
+}
+
+// notify observers at most a few times a second unless forced. p.mu must be
+// held.
+func (p *progress) notify(force bool) {
+	now := time.Now()
+	if !force && now.Sub(p.notified) < 250*time.Millisecond {
+		return
+	}
+	p.notified = now
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// Wait returns a channel which is closed when progress is made.
+func (p *progress) Wait() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.changed
+}
+
+func (p *progress) Status() web.Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := web.Status{
+		File:     p.file,
+		Read:     p.read,
+		Size:     p.size,
+		Sections: make(map[string]int, len(p.sections)),
+		Elapsed:  time.Since(p.start).Seconds(),
+		ETA:      -1,
+		Ready:    p.ready,
+		Error:    p.err,
+	}
+	for k, v := range p.sections {
+		s.Sections[k] = v
+		s.Records += v
+	}
+	if p.size > 0 {
+		s.Percent = int(float64(p.read) / float64(p.size) * 100)
+		if p.read > 0 {
+			elapsed := time.Since(p.fileStart).Seconds()
+			s.ETA = elapsed * float64(p.size-p.read) / float64(p.read)
+		}
+	}
+	return s
+}
+
+// reader counts the bytes read from rc.
+func (p *progress) reader(rc io.ReadCloser) io.ReadCloser {
+	return &progressReader{rc: rc, p: p}
+}
+
+type progressReader struct {
+	rc io.ReadCloser
+	p  *progress
+}
+
+func (r *progressReader) Read(buf []byte) (int, error) {
+	n, err := r.rc.Read(buf)
+	r.p.mu.Lock()
+	r.p.read += int64(n)
+	r.p.notify(false)
+	r.p.mu.Unlock()
+	return n, err
+}
+
+func (r *progressReader) Close() error { return r.rc.Close() }
+
+// report progress to out until stop is called: a progress bar if out is a
+// terminal or a line every few seconds otherwise.
+func (p *progress) report(out *os.File) (stop func()) {
+	tty := false
+	if fi, err := out.Stat(); err == nil {
+		tty = fi.Mode()&os.ModeCharDevice != 0
+	}
+	interval := 3 * time.Second
+	if tty {
+		interval = 200 * time.Millisecond
+		p.mu.Lock()
+		p.tty = out == os.Stderr
+		p.mu.Unlock()
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				if tty {
+					fmt.Fprintf(out, "\r%s\r", strings.Repeat(" ", 79))
+				}
+				return
+			case <-t.C:
+			}
+			s := p.Status()
+			if tty {
+				fmt.Fprintf(out, "\r%-79s", bar(s, 79))
+			} else if s.Size > 0 {
+				fmt.Fprintf(out, "%s: %d/%d (%d%%) done\n", s.File, s.Read, s.Size, s.Percent)
+			} else {
+				fmt.Fprintf(out, "%s: %d done\n", s.File, s.Read)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+		p.mu.Lock()
+		p.tty = false
+		p.mu.Unlock()
+	}
+}
+
+// logf prints a line to stderr without garbling the progress bar.
+func (p *progress) logf(format string, args ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tty {
+		fmt.Fprintf(os.Stderr, "\r%s\r", strings.Repeat(" ", 79))
+	}
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// bar renders s as a progress bar at most width characters wide.
+func bar(s web.Status, width int) string {
+	name := path.Base(s.File)
+	if len(name) > 20 {
+		name = name[:17] + "..."
+	}
+	if s.Size <= 0 {
+		return fmt.Sprintf("%s %d MB %d records", name, s.Read>>20, s.Records)
+	}
+	eta := "?"
+	if s.ETA >= 0 {
+		eta = (time.Duration(s.ETA) * time.Second).String()
+	}
+	info := fmt.Sprintf(" %3d%% %d/%d MB eta %s", s.Percent, s.Read>>20, s.Size>>20, eta)
+	n := width - len(name) - len(info) - 3
+	if n < 10 {
+		return name + info
+	}
+	filled := n * s.Percent / 100
+	if filled > n {
+		filled = n
+	}
+	return name + " [" + strings.Repeat("=", filled) + strings.Repeat(" ", n-filled) + "]" + info
+}
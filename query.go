@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// condition is a -where filter on a record field such as race=dragon,
+// name~urist or birth_year<100.
+type condition struct {
+	field string
+	op    string
+	value string
+}
+
+// conditionOps are longest first so the longer of two operators starting at
+// the same position wins
+var conditionOps = []string{"!=", ">=", "<=", "=", "~", ">", "<"}
+
+// parseCondition splits s at the first operator in it.
+func parseCondition(s string) (condition, error) {
+	at, op := -1, ""
+	for _, o := range conditionOps {
+		if i := strings.Index(s, o); i != -1 && (at == -1 || i < at) {
+			at, op = i, o
+		}
+	}
+	if at > 0 {
+		return condition{field: s[:at], op: op, value: s[at+len(op):]}, nil
+	}
+	return condition{}, fmt.Errorf("invalid condition %q: expected field, operator (%s) and value", s, strings.Join(conditionOps, " "))
+}
+
+func (c condition) match(rec []field) bool {
+	for _, f := range rec {
+		if f.name != c.field {
+			continue
+		}
+		v := tableValue(f.value)
+		switch c.op {
+		case "=":
+			return strings.EqualFold(v, c.value)
+		case "!=":
+			return !strings.EqualFold(v, c.value)
+		case "~":
+			return strings.Contains(strings.ToLower(v), strings.ToLower(c.value))
+		}
+		n, err1 := strconv.ParseFloat(v, 64)
+		want, err2 := strconv.ParseFloat(c.value, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		switch c.op {
+		case ">":
+			return n > want
+		case "<":
+			return n < want
+		case ">=":
+			return n >= want
+		case "<=":
+			return n <= want
+		}
+	}
+	return false
+}
+
+// stringsFlag collects a repeatable flag
+type stringsFlag []string
+
+func (s *stringsFlag) String() string     { return strings.Join(*s, ",") }
+func (s *stringsFlag) Set(v string) error { *s = append(*s, v); return nil }
+
+func queryMain(args []string) {
+	fs := newFlagSet("query")
+	section := fs.String("section", "figures", "section to search")
+	format := fs.String("format", "table", "output format: table, json, ndjson or yaml")
+	fields := fs.String("fields", "", "comma separated fields to include (default all; table defaults to simple fields)")
+	var where stringsFlag
+	fs.Var(&where, "where", "condition records must match: field=value, field!=value, field~substring, field<number or field>number (repeatable)")
+	parseFlags(fs, args, 1)
+
+	if !validSection(*section) {
+		fmt.Fprintf(os.Stderr, "unknown section %q\n", *section)
+		os.Exit(exitUsage)
+	}
+	d := &dumper{sections: []string{*section}, format: *format}
+	switch d.format {
+	case "table", "json", "ndjson", "yaml":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", d.format)
+		os.Exit(exitUsage)
+	}
+	if *fields != "" {
+		d.fields = strings.Split(*fields, ",")
+	}
+	for _, w := range where {
+		c, err := parseCondition(w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(exitUsage)
+		}
+		d.where = append(d.where, c)
+	}
+
+	world := load(fs.Args())
+	if err := d.dump(os.Stdout, world); err != nil {
+		fmt.Fprintf(os.Stderr, "error dumping: %v\n", err)
+		os.Exit(exitIO)
+	}
+	if d.matched == 0 {
+		os.Exit(exitNoMatch)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestParseCondition(t *testing.T) {
+	cases := []struct {
+		in   string
+		want condition
+		err  bool
+	}{
+		{in: "race=dragon", want: condition{"race", "=", "dragon"}},
+		{in: "race!=dragon", want: condition{"race", "!=", "dragon"}},
+		{in: "birth_year>=100", want: condition{"birth_year", ">=", "100"}},
+		{in: "birth_year<=100", want: condition{"birth_year", "<=", "100"}},
+		{in: "birth_year>100", want: condition{"birth_year", ">", "100"}},
+		{in: "birth_year<100", want: condition{"birth_year", "<", "100"}},
+		{in: "name~urist", want: condition{"name", "~", "urist"}},
+		{in: "name~a=b", want: condition{"name", "~", "a=b"}},
+		{in: "name<x>=y", want: condition{"name", "<", "x>=y"}},
+		{in: "name=a!=b", want: condition{"name", "=", "a!=b"}},
+		{in: "name=", want: condition{"name", "=", ""}},
+		{in: "=dragon", err: true},
+		{in: "dragon", err: true},
+		{in: "", err: true},
+	}
+	for _, c := range cases {
+		got, err := parseCondition(c.in)
+		switch {
+		case c.err && err == nil:
+			t.Errorf("parseCondition(%q) = %+v, want an error", c.in, got)
+		case !c.err && err != nil:
+			t.Errorf("parseCondition(%q): %v", c.in, err)
+		case got != c.want:
+			t.Errorf("parseCondition(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
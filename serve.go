@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runserver serves hs until SIGINT or SIGTERM and then shuts it down
+// gracefully, waiting for requests in progress to finish.
+func runserver(hs *http.Server) {
+	done := make(chan struct{})
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		log.Printf("received %s; shutting down", <-sig)
+		signal.Stop(sig)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := hs.Shutdown(ctx); err != nil {
+			log.Printf("error shutting down: %v", err)
+		}
+		close(done)
+	}()
+	if err := hs.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+	<-done
+}
+
+// logRequests logs the path of every request served by h.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer log.Print(r.URL.Path)
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+)
+
+func statsMain(args []string) {
+	fs := newFlagSet("stats")
+	parseFlags(fs, args, 1)
+
+	world := load(fs.Args())
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	if world.Name != "" {
+		fmt.Fprintf(w, "%s, %s\n\n", world.Name, world.AltName)
+	}
+	for _, s := range dumpSections {
+		fmt.Fprintf(w, "%s\t%d\n", s.name, reflect.ValueOf(s.records(world)).Len())
+	}
+	for _, s := range world.EraStats() {
+		fmt.Fprintln(w)
+		if s.Era != nil {
+			end := "present"
+			if s.Era.EndYear != -1 {
+				end = fmt.Sprint(s.Era.EndYear)
+			}
+			fmt.Fprintf(w, "%s\t%d-%s\n", s.Era.Name, s.Era.StartYear, end)
+		}
+		fmt.Fprintf(w, "  events\t%d\n", s.Events)
+		fmt.Fprintf(w, "  births\t%d\n", s.Births)
+		fmt.Fprintf(w, "  deaths\t%d\n", s.Deaths)
+		fmt.Fprintf(w, "  sites founded\t%d\n", s.SitesFounded)
+		fmt.Fprintf(w, "  sites destroyed\t%d\n", s.SitesDestroyed)
+		types := make([]string, 0, len(s.EventTypes))
+		for t := range s.EventTypes {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			fmt.Fprintf(w, "  %s\t%d\n", t, s.EventTypes[t])
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing stats: %v\n", err)
+		os.Exit(exitIO)
+	}
+}
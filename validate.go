@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+func validateMain(args []string) {
+	fs := newFlagSet("validate")
+	format := fs.String("format", "text", "output format: text or json")
+	parseFlags(fs, args, 1)
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(exitUsage)
+	}
+
+	// load exits with exitIO or exitParse if the world can't be loaded
+	report := load(fs.Args()).Validate()
+
+	var err error
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = writeReport(os.Stdout, report)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing report: %v\n", err)
+		os.Exit(exitIO)
+	}
+	if report.Problems > 0 {
+		os.Exit(exitInvalid)
+	}
+}
+
+// writeReport writes a summary line per check followed by its examples.
+func writeReport(out io.Writer, r *lg.Report) error {
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, "%d problems in %d records\n", r.Problems, r.Records)
+	for _, c := range r.Checks {
+		fmt.Fprintf(w, "\n%s: %s %s (%d)\n", c.Kind, c.Section, c.Field, c.Count)
+		for _, p := range c.Examples {
+			fmt.Fprintf(w, "  %s: %s\n", p.Record, p.Message)
+		}
+		if n := c.Count - len(c.Examples); n > 0 {
+			fmt.Fprintf(w, "  ... and %d more\n", n)
+		}
+	}
+	return w.Flush()
+}
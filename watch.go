@@ -0,0 +1,126 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/schmichael/legendarygopher/lg"
+	"github.com/schmichael/legendarygopher/web"
+)
+
+// watch polls the legends file in args[0] and reloads the World served by s
+// when it, its legends_plus.xml or a newer export of the same region in its
+// directory changes.
+func watch(s *web.Server, args []string, interval time.Duration) {
+	if args[0] == "-" {
+		log.Printf("unable to watch stdin for changes")
+		return
+	}
+	file := args[0]
+	mod, _ := exportState(file)
+	var pendingMod time.Time
+	var pendingSize int64 = -1
+	for range time.Tick(interval) {
+		fn := latestExport(file)
+		m, sz := exportState(fn)
+		if fn == file && !m.After(mod) {
+			continue
+		}
+		// Wait for DF to finish writing the export
+		if !m.Equal(pendingMod) || sz != pendingSize {
+			pendingMod, pendingSize = m, sz
+			continue
+		}
+
+		reloadArgs := args
+		if fn != file {
+			reloadArgs = []string{fn}
+		}
+		log.Printf("reloading %s", fn)
+		reload(s, reloadArgs)
+		file, mod = fn, m
+		pendingSize = -1
+	}
+}
+
+// reload loads the World from args and swaps it with the World served by
+// s.
+func reload(s *web.Server, args []string) {
+	old := s.Current()
+	start := time.Now()
+	r := &web.Reload{Time: start, File: args[0]}
+	world, files, err := loadFiles(args, newProgress())
+	r.Duration = time.Since(start).Truncate(time.Millisecond)
+	if err != nil {
+		log.Printf("error reloading %s: %v", args[0], err)
+		r.Error = err.Error()
+		s.SetWorld(&web.Loaded{World: old.World, Files: old.Files, Maps: old.Maps, Closer: old.Closer, Reload: r})
+		return
+	}
+	r.Changes = changes(old.World, world)
+	s.SetWorld(&web.Loaded{World: world, Files: files.all, Maps: files.maps, Closer: files, Reload: r})
+	log.Printf("reloaded %s in %s", args[0], r.Duration)
+}
+
+// changes returns the sections whose number of records changed.
+func changes(before, after *lg.World) []*web.SectionChange {
+	var out []*web.SectionChange
+	for _, sec := range dumpSections {
+		c := &web.SectionChange{
+			Section: sec.name,
+			Before:  reflect.ValueOf(sec.records(before)).Len(),
+			After:   reflect.ValueOf(sec.records(after)).Len(),
+		}
+		if c.Before != c.After {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// latestExport returns the newest legends.xml in fn's directory from the
+// same region (DF names exports region1-00250-01-01-legends.xml) or fn.
+func latestExport(fn string) string {
+	base := filepath.Base(fn)
+	i := strings.Index(base, "-")
+	if i == -1 || !strings.Contains(base, "-legends.xml") {
+		return fn
+	}
+	ext := base[strings.Index(base, "-legends.xml")+len("-legends.xml"):]
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(fn), base[:i]+"-*-legends.xml"+ext))
+	latest, latestMod := fn, time.Time{}
+	if fi, err := os.Stat(fn); err == nil {
+		latestMod = fi.ModTime()
+	}
+	for _, m := range matches {
+		if fi, err := os.Stat(m); err == nil && fi.ModTime().After(latestMod) {
+			latest, latestMod = m, fi.ModTime()
+		}
+	}
+	return latest
+}
+
+// exportState returns the latest modification time and total size of a
+// legends file and its legends_plus.xml.
+func exportState(fn string) (time.Time, int64) {
+	var mod time.Time
+	var size int64
+	for _, f := range []string{fn, plusFile(fn)} {
+		if f == "" {
+			continue
+		}
+		fi, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		if fi.ModTime().After(mod) {
+			mod = fi.ModTime()
+		}
+		size += fi.Size()
+	}
+	return mod, size
+}
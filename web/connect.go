@@ -0,0 +1,80 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+// connection is the data of the connect template and the json of
+// /api/connect: the shortest path between two records.
+type connection struct {
+	World *lg.World `json:"-"`
+	From  string    `json:"from"`
+	To    string    `json:"to"`
+	Path  []hop     `json:"path"`
+	Error string    `json:"error,omitempty"`
+}
+
+// hop is an edge of a connection and, if it leads to or from an event, the
+// event explaining it.
+type hop struct {
+	lg.Edge
+	Event       *lg.Event `json:"event,omitempty"`
+	Description string    `json:"description,omitempty"`
+}
+
+// connect finds the connection between the records in ?from and ?to and
+// returns the HTTP status to serve it with.
+func (s *Server) connect(r *http.Request) (*connection, int) {
+	world := s.current(r).World
+	c := &connection{World: world, From: r.URL.Query().Get("from"), To: r.URL.Query().Get("to"), Path: []hop{}}
+	if c.From == "" && c.To == "" {
+		return c, 200
+	}
+	from, err := lg.ParseNode(c.From)
+	if err == nil && !world.HasNode(from) {
+		err = fmt.Errorf("%s not found", from)
+	}
+	if err != nil {
+		c.Error = err.Error()
+		return c, 400
+	}
+	to, err := lg.ParseNode(c.To)
+	if err == nil && !world.HasNode(to) {
+		err = fmt.Errorf("%s not found", to)
+	}
+	if err != nil {
+		c.Error = err.Error()
+		return c, 400
+	}
+	path := world.Connect(from, to)
+	if path == nil && from != to {
+		c.Error = fmt.Sprintf("%s and %s aren't connected", world.NodeName(from), world.NodeName(to))
+		return c, 404
+	}
+	for _, e := range path {
+		h := hop{Edge: e}
+		for _, n := range []lg.Node{e.From, e.To} {
+			if n.Kind == "event" {
+				h.Event = world.Event(n.ID)
+				h.Description = world.NodeName(n)
+			}
+		}
+		c.Path = append(c.Path, h)
+	}
+	return c, 200
+}
+
+func (s *Server) connectHandler(w http.ResponseWriter, r *http.Request) {
+	c, status := s.connect(r)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	s.execute(w, c.World, "connect", c)
+}
+
+func (s *Server) connectAPIHandler(w http.ResponseWriter, r *http.Request) {
+	c, status := s.connect(r)
+	writeJSON(w, status, c)
+}
@@ -0,0 +1,113 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+// page is the data of the layout partials: the title of the page and the
+// breadcrumbs leading to it.
+type page struct {
+	World  *lg.World
+	Title  string
+	Crumbs []crumb
+}
+
+type crumb struct {
+	Label string
+	Href  string
+}
+
+// funcs are the functions templates can call besides html/template's
+// builtins, looking records up in w. They're part of the template data
+// contract in TEMPLATES.md so don't rename or remove them.
+func (s *Server) funcs(w *lg.World) template.FuncMap {
+	return template.FuncMap{
+		"prefix": func() string { return s.opts.Prefix },
+		"page":   s.page,
+		"year":   year,
+		"figureLink": func(id int) template.HTML {
+			if f := w.Figure(id); f != nil {
+				return s.link("/figures/"+fmt.Sprint(id), f.Name)
+			}
+			return unknown("figure", id)
+		},
+		"siteLink": func(id int) template.HTML {
+			if site := w.Site(id); site != nil {
+				return s.link("/sites/"+fmt.Sprint(id), site.Name)
+			}
+			return unknown("site", id)
+		},
+		"entityLink": func(id int) template.HTML {
+			if e := w.Entity(id); e != nil {
+				return s.link("/entities/"+fmt.Sprint(id), e.Name)
+			}
+			return unknown("entity", id)
+		},
+		"artifactLink": func(id int) template.HTML {
+			if a := w.Artifact(id); a != nil {
+				return s.link("/artifacts?id="+fmt.Sprint(id), a.Name)
+			}
+			return unknown("artifact", id)
+		},
+		"writtenContentLink": func(id int) template.HTML {
+			if c := w.WrittenContent(id); c != nil {
+				return s.link("/writtencontents/"+fmt.Sprint(id), c.Title)
+			}
+			return unknown("written content", id)
+		},
+		"event": func(e *lg.Event) string {
+			return w.RenderEvent(e)
+		},
+		"nodeLink": func(n lg.Node) template.HTML {
+			if !w.HasNode(n) {
+				return unknown(n.Kind, n.ID)
+			}
+			switch n.Kind {
+			case "figure":
+				return s.link(fmt.Sprintf("/figures/%d", n.ID), w.NodeName(n))
+			case "site":
+				return s.link(fmt.Sprintf("/sites/%d", n.ID), w.NodeName(n))
+			case "entity":
+				return s.link(fmt.Sprintf("/entities/%d", n.ID), w.NodeName(n))
+			case "artifact":
+				return s.link(fmt.Sprintf("/artifacts?id=%d", n.ID), w.NodeName(n))
+			}
+			return s.link(fmt.Sprintf("/events?id=%d", n.ID), w.NodeName(n))
+		},
+	}
+}
+
+// page returns the layout data for a page titled title. crumbs are label
+// and path pairs of the pages leading to it after Home. The index page has
+// no title.
+func (s *Server) page(w *lg.World, title interface{}, crumbs ...string) page {
+	p := page{World: w, Title: fmt.Sprint(title)}
+	if p.Title == "" {
+		return p
+	}
+	p.Crumbs = append(p.Crumbs, crumb{"Home", s.opts.Prefix + "/"})
+	for i := 0; i+1 < len(crumbs); i += 2 {
+		p.Crumbs = append(p.Crumbs, crumb{crumbs[i], s.opts.Prefix + crumbs[i+1]})
+	}
+	return p
+}
+
+func (s *Server) link(href, name string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<a class="proper" href="%s">%s</a>`,
+		template.HTMLEscapeString(s.opts.Prefix+href), template.HTMLEscapeString(name)))
+}
+
+func unknown(kind string, id int) template.HTML {
+	return template.HTML(fmt.Sprintf("unknown %s %d", kind, id))
+}
+
+// year formats a year from the legends: -1 means unknown.
+func year(y int) string {
+	if y == -1 {
+		return "?"
+	}
+	return fmt.Sprint(y)
+}
@@ -0,0 +1,296 @@
+package web
+
+import (
+	"cmp"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+// perPage is the number of rows on a list page unless ?per asks for up to
+// maxPerPage.
+const (
+	perPage    = 100
+	maxPerPage = 1000
+)
+
+// list describes a list page of records of type T: its table columns and
+// filters. Every list can be filtered by ?id and by ?name, a substring of
+// what name returns.
+type list[T any] struct {
+	template string
+	records  func(w *lg.World) []T
+	id       func(T) int
+	name     func(w *lg.World, rec T) string
+	columns  []column[T]
+
+	// filter returns whether a record matches the filters in q besides name
+	// and id or nil if the list has no other filters.
+	filter func(w *lg.World, q url.Values) func(T) bool
+
+	// lookup returns the records which may match the filters in q from an
+	// index or false to filter every record.
+	lookup func(w *lg.World, q url.Values) ([]T, bool)
+
+	// options returns the choices of select filters by parameter.
+	options func(w *lg.World) map[string][]string
+
+	// summary returns the Summary of the filtered records or is nil.
+	summary func(w *lg.World, rows []T) interface{}
+}
+
+// column of a list table. It can be sorted by if it has a key which returns
+// an int or a string.
+type column[T any] struct {
+	name  string
+	label string
+	key   func(w *lg.World, rec T) interface{}
+}
+
+// header is a column of a list table as templates see it.
+type header struct {
+	Name     string
+	Label    string
+	Sortable bool
+}
+
+// listPage is the data of list templates: a page of the filtered and sorted
+// records in Rows.
+type listPage struct {
+	World   *lg.World
+	Rows    interface{}
+	Columns []header
+	Options map[string][]string
+	Summary interface{}
+
+	// Total is the number of records matching the filters and All the
+	// number of records in the list.
+	Total int
+	All   int
+
+	Page    int
+	Pages   int
+	PerPage int
+	Sort    string
+	Desc    bool
+
+	path  string
+	query url.Values
+}
+
+// Filter returns the value of a filter parameter.
+func (p *listPage) Filter(name string) string { return p.query.Get(name) }
+
+// FilterID returns the ID in a filter parameter or -1.
+func (p *listPage) FilterID(name string) int { return idParam(p.query, name) }
+
+// Filtered returns whether any filters are set.
+func (p *listPage) Filtered() bool { return p.Total != p.All }
+
+// SortURL returns the URL of the list sorted by col, reversing the order if
+// it's already sorted by it.
+func (p *listPage) SortURL(col string) string {
+	q := p.url()
+	q.Del("page")
+	q.Del("desc")
+	q.Set("sort", col)
+	if p.Sort == col && !p.Desc {
+		q.Set("desc", "1")
+	}
+	return p.path + "?" + q.Encode()
+}
+
+// SortMark returns an arrow if the list is sorted by col.
+func (p *listPage) SortMark(col string) string {
+	switch {
+	case p.Sort != col:
+		return ""
+	case p.Desc:
+		return "▼"
+	default:
+		return "▲"
+	}
+}
+
+// FilterURL returns the URL of the list with the filter name set to value.
+func (p *listPage) FilterURL(name, value string) string {
+	q := p.url()
+	q.Del("page")
+	q.Set(name, value)
+	return p.path + "?" + q.Encode()
+}
+
+// PageURL returns the URL of page n of the list.
+func (p *listPage) PageURL(n int) string {
+	q := p.url()
+	q.Set("page", strconv.Itoa(n))
+	return p.path + "?" + q.Encode()
+}
+
+// PrevURL returns the URL of the previous page or "" on the first page.
+func (p *listPage) PrevURL() string {
+	if p.Page <= 1 {
+		return ""
+	}
+	return p.PageURL(p.Page - 1)
+}
+
+// NextURL returns the URL of the next page or "" on the last page.
+func (p *listPage) NextURL() string {
+	if p.Page >= p.Pages {
+		return ""
+	}
+	return p.PageURL(p.Page + 1)
+}
+
+func (p *listPage) url() url.Values {
+	q := url.Values{}
+	for k, v := range p.query {
+		if len(v) > 0 && v[0] != "" {
+			q[k] = v
+		}
+	}
+	return q
+}
+
+// serveList serves a list page of l.
+func serveList[T any](s *Server, l *list[T]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		world := s.current(r).World
+		q := r.URL.Query()
+		all := l.records(world)
+
+		match := func(T) bool { return true }
+		if l.filter != nil {
+			match = l.filter(world, q)
+		}
+		name := strings.ToLower(strings.TrimSpace(q.Get("name")))
+		id, idErr := strconv.Atoi(q.Get("id"))
+		recs := all
+		if l.lookup != nil {
+			if found, ok := l.lookup(world, q); ok {
+				recs = found
+			}
+		}
+		var rows []T
+		for _, rec := range recs {
+			if name != "" && !strings.Contains(strings.ToLower(l.name(world, rec)), name) {
+				continue
+			}
+			if idErr == nil && l.id(rec) != id {
+				continue
+			}
+			if match(rec) {
+				rows = append(rows, rec)
+			}
+		}
+
+		p := &listPage{
+			World:   world,
+			Total:   len(rows),
+			All:     len(all),
+			PerPage: perPage,
+			Desc:    q.Get("desc") != "",
+			path:    s.opts.Prefix + r.URL.Path,
+			query:   q,
+		}
+		for _, c := range l.columns {
+			p.Columns = append(p.Columns, header{c.name, c.label, c.key != nil})
+			if c.name == q.Get("sort") && c.key != nil {
+				p.Sort = c.name
+				sortBy(rows, func(rec T) interface{} { return c.key(world, rec) }, p.Desc)
+			}
+		}
+		if l.options != nil {
+			p.Options = l.options(world)
+		}
+		if l.summary != nil {
+			p.Summary = l.summary(world, rows)
+		}
+
+		if n, err := strconv.Atoi(q.Get("per")); err == nil && n > 0 {
+			p.PerPage = min(n, maxPerPage)
+		}
+		p.Pages = max(1, (len(rows)+p.PerPage-1)/p.PerPage)
+		p.Page = 1
+		if n, err := strconv.Atoi(q.Get("page")); err == nil {
+			p.Page = min(max(n, 1), p.Pages)
+		}
+		start := (p.Page - 1) * p.PerPage
+		p.Rows = rows[start:min(start+p.PerPage, len(rows))]
+		s.execute(w, world, l.template, p)
+	}
+}
+
+// sortBy sorts recs by key, keeping the order of records with equal keys.
+// Strings are compared case insensitively.
+func sortBy[T any](recs []T, key func(T) interface{}, desc bool) {
+	type keyed struct {
+		key interface{}
+		rec T
+	}
+	ks := make([]keyed, len(recs))
+	for i, rec := range recs {
+		k := key(rec)
+		if s, ok := k.(string); ok {
+			k = strings.ToLower(s)
+		}
+		ks[i] = keyed{k, rec}
+	}
+	sort.SliceStable(ks, func(i, j int) bool {
+		c := compareKeys(ks[i].key, ks[j].key)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	for i := range ks {
+		recs[i] = ks[i].rec
+	}
+}
+
+func compareKeys(a, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case string:
+		return cmp.Compare(a, b.(string))
+	}
+	return 0
+}
+
+// distinct returns the sorted non-empty values of field in recs.
+func distinct[T any](recs []T, field func(T) string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, rec := range recs {
+		if v := field(rec); v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// yearRange returns whether a year is within the from and to filters in q.
+func yearRange(q url.Values) func(year int) bool {
+	from, errFrom := strconv.Atoi(q.Get("from"))
+	to, errTo := strconv.Atoi(q.Get("to"))
+	return func(year int) bool {
+		return (errFrom != nil || year >= from) && (errTo != nil || year <= to)
+	}
+}
+
+// idParam returns the ID in the query parameter name or -1 if it isn't one.
+func idParam(q url.Values, name string) int {
+	id, err := strconv.Atoi(q.Get(name))
+	if err != nil {
+		return -1
+	}
+	return id
+}
@@ -0,0 +1,300 @@
+package web
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+var artifactList = &list[*lg.Artifact]{
+	template: "artifacts",
+	records:  func(w *lg.World) []*lg.Artifact { return w.Artifacts },
+	id:       func(a *lg.Artifact) int { return a.ID },
+	name:     func(_ *lg.World, a *lg.Artifact) string { return a.Name },
+	columns: []column[*lg.Artifact]{
+		{"id", "#", func(_ *lg.World, a *lg.Artifact) interface{} { return a.ID }},
+		{"name", "Name", func(_ *lg.World, a *lg.Artifact) interface{} { return a.Name }},
+		{"item", "Item", func(_ *lg.World, a *lg.Artifact) interface{} { return a.Item }},
+	},
+}
+
+var entityList = &list[*lg.Entity]{
+	template: "entities",
+	records:  func(w *lg.World) []*lg.Entity { return w.Entities },
+	id:       func(e *lg.Entity) int { return e.ID },
+	name:     func(_ *lg.World, e *lg.Entity) string { return e.Name },
+	columns: []column[*lg.Entity]{
+		{"id", "#", func(_ *lg.World, e *lg.Entity) interface{} { return e.ID }},
+		{"name", "Name", func(_ *lg.World, e *lg.Entity) interface{} { return e.Name }},
+		{"leaders", "Leaders", func(_ *lg.World, e *lg.Entity) interface{} { return len(e.Leaders) }},
+	},
+	// Skip the nameless entities legends.xml is full of unless asked for
+	filter: func(w *lg.World, q url.Values) func(*lg.Entity) bool {
+		all := q.Get("unnamed") != ""
+		return func(e *lg.Entity) bool { return all || e.Name != "" }
+	},
+}
+
+var eventList = &list[*lg.Event]{
+	template: "events",
+	records:  func(w *lg.World) []*lg.Event { return w.Events },
+	id:       func(e *lg.Event) int { return e.ID },
+	name:     func(w *lg.World, e *lg.Event) string { return w.RenderEvent(e) },
+	columns: []column[*lg.Event]{
+		{"id", "#", func(_ *lg.World, e *lg.Event) interface{} { return e.ID }},
+		{"year", "Year", func(_ *lg.World, e *lg.Event) interface{} { return e.Year }},
+		{"type", "Type", func(_ *lg.World, e *lg.Event) interface{} { return e.Type }},
+		{"season", "Season", func(_ *lg.World, e *lg.Event) interface{} { return e.Seconds }},
+		{"event", "Event", func(w *lg.World, e *lg.Event) interface{} { return w.RenderEvent(e) }},
+		{"participants", "Participants", func(w *lg.World, e *lg.Event) interface{} {
+			ps := e.Participants()
+			names := make([]string, 0, len(ps))
+			for _, p := range ps {
+				names = append(names, w.NodeName(lg.Node{Kind: p.Kind, ID: p.ID}))
+			}
+			return strings.Join(names, ", ")
+		}},
+		{"location", "Location", func(w *lg.World, e *lg.Event) interface{} { return eventLocation(w, e) }},
+	},
+	// participant is a substring of the name of an involved figure or entity,
+	// era is the name of an era and figure, entity and site are IDs.
+	filter: func(w *lg.World, q url.Values) func(*lg.Event) bool {
+		typ, era, years := q.Get("type"), q.Get("era"), yearRange(q)
+		participant := strings.ToLower(strings.TrimSpace(q.Get("participant")))
+		figure, entity, site := idParam(q, "figure"), idParam(q, "entity"), idParam(q, "site")
+		return func(e *lg.Event) bool {
+			switch {
+			case typ != "" && e.Type != typ, !years(e.Year):
+				return false
+			case era != "" && w.Era(e.Year).String() != era:
+				return false
+			case site != -1 && e.SiteID != site:
+				return false
+			case figure != -1 && !slices.Contains(e.FigureIDs(), figure):
+				return false
+			case entity != -1 && !slices.Contains(e.EntityIDs(), entity):
+				return false
+			}
+			return participant == "" || involves(w, e, participant)
+		}
+	},
+	lookup: func(w *lg.World, q url.Values) ([]*lg.Event, bool) {
+		if id := idParam(q, "figure"); id != -1 {
+			return w.EventsForFigure(id), true
+		}
+		if id := idParam(q, "entity"); id != -1 {
+			return w.EventsForEntity(id), true
+		}
+		if id := idParam(q, "site"); id != -1 {
+			return w.EventsForSite(id), true
+		}
+		from, errFrom := strconv.Atoi(q.Get("from"))
+		to, errTo := strconv.Atoi(q.Get("to"))
+		if errFrom != nil && errTo != nil {
+			return nil, false
+		}
+		if errFrom != nil {
+			from = math.MinInt
+		}
+		if errTo != nil {
+			to = math.MaxInt
+		}
+		return w.EventsInYears(from, to), true
+	},
+	options: func(w *lg.World) map[string][]string {
+		eras := make([]string, len(w.Eras))
+		for i, e := range w.Eras {
+			eras[i] = e.Name
+		}
+		return map[string][]string{
+			"type": distinct(w.Events, func(e *lg.Event) string { return e.Type }),
+			"era":  eras,
+		}
+	},
+	summary: func(w *lg.World, rows []*lg.Event) interface{} {
+		return countTypes(rows)
+	},
+}
+
+// typeCount is the number of events of a type.
+type typeCount struct {
+	Type  string
+	Count int
+}
+
+// countTypes counts events by type, most common first.
+func countTypes(events []*lg.Event) []typeCount {
+	counts := map[string]int{}
+	for _, e := range events {
+		counts[e.Type]++
+	}
+	out := make([]typeCount, 0, len(counts))
+	for t, n := range counts {
+		out = append(out, typeCount{t, n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Type < out[j].Type
+	})
+	return out
+}
+
+// eventLocation is the name of the site or region an event happened in or
+// its coordinates.
+func eventLocation(w *lg.World, e *lg.Event) string {
+	if e.SiteID != -1 {
+		return w.NodeName(lg.Node{Kind: "site", ID: e.SiteID})
+	}
+	if r := w.Region(e.SubregionID); r != nil {
+		return r.Name
+	}
+	return e.Coords
+}
+
+// involves returns whether the name of a figure, entity or artifact involved
+// in e contains the lowercase name.
+func involves(w *lg.World, e *lg.Event, name string) bool {
+	for _, p := range e.Participants() {
+		var n string
+		switch p.Kind {
+		case "figure":
+			if f := w.Figure(p.ID); f != nil {
+				n = f.Name
+			}
+		case "entity":
+			if ent := w.Entity(p.ID); ent != nil {
+				n = ent.Name
+			}
+		case "artifact":
+			if a := w.Artifact(p.ID); a != nil {
+				n = a.Name
+			}
+		}
+		if strings.Contains(strings.ToLower(n), name) {
+			return true
+		}
+	}
+	return false
+}
+
+var figureList = &list[*lg.Figure]{
+	template: "figures",
+	records:  func(w *lg.World) []*lg.Figure { return w.Figures },
+	id:       func(f *lg.Figure) int { return f.ID },
+	name:     func(_ *lg.World, f *lg.Figure) string { return f.Name },
+	columns: []column[*lg.Figure]{
+		{"id", "#", func(_ *lg.World, f *lg.Figure) interface{} { return f.ID }},
+		{"name", "Name", func(_ *lg.World, f *lg.Figure) interface{} { return f.Name }},
+		{"race", "Race", func(_ *lg.World, f *lg.Figure) interface{} { return f.Race }},
+		{"caste", "Caste", func(_ *lg.World, f *lg.Figure) interface{} { return f.Caste }},
+		{"birth", "Born", func(_ *lg.World, f *lg.Figure) interface{} { return f.BirthYear }},
+		{"death", "Died", func(_ *lg.World, f *lg.Figure) interface{} { return f.DiedIn }},
+		{"age", "Age", func(_ *lg.World, f *lg.Figure) interface{} { return f.Age }},
+		{"appeared", "Appeared", func(_ *lg.World, f *lg.Figure) interface{} { return f.Appeared }},
+	},
+	// from and to filter the birth year
+	filter: func(w *lg.World, q url.Values) func(*lg.Figure) bool {
+		race, status, tag, years := q.Get("race"), q.Get("status"), q.Get("tag"), yearRange(q)
+		return func(f *lg.Figure) bool {
+			switch {
+			case race != "" && f.Race != race:
+				return false
+			case tag != "" && !f.Is(tag):
+				return false
+			case status == "alive" && !f.Alive:
+				return false
+			case status == "dead" && f.Alive:
+				return false
+			}
+			return years(f.BirthYear)
+		}
+	},
+	options: func(w *lg.World) map[string][]string {
+		var tags []string
+		for _, t := range lg.FigureTags {
+			if slices.ContainsFunc(w.Figures, func(f *lg.Figure) bool { return f.Is(t) }) {
+				tags = append(tags, t)
+			}
+		}
+		return map[string][]string{
+			"race": distinct(w.Figures, func(f *lg.Figure) string { return f.Race }),
+			"tag":  tags,
+		}
+	},
+}
+
+var siteList = &list[*lg.Site]{
+	template: "sites",
+	records:  func(w *lg.World) []*lg.Site { return w.Sites },
+	id:       func(s *lg.Site) int { return s.ID },
+	name:     func(_ *lg.World, s *lg.Site) string { return s.Name },
+	columns: []column[*lg.Site]{
+		{"id", "#", func(_ *lg.World, s *lg.Site) interface{} { return s.ID }},
+		{"name", "Name", func(_ *lg.World, s *lg.Site) interface{} { return s.Name }},
+		{"type", "Type", func(_ *lg.World, s *lg.Site) interface{} { return s.Type }},
+		{"coords", "Coordinates", func(_ *lg.World, s *lg.Site) interface{} { return coordsKey(s.Coords) }},
+		{"owner", "Owner", func(w *lg.World, s *lg.Site) interface{} {
+			if o := s.Owner(); o != nil {
+				if e := w.Entity(o.CivID); e != nil {
+					return e.Name
+				}
+			}
+			return ""
+		}},
+	},
+	filter: func(w *lg.World, q url.Values) func(*lg.Site) bool {
+		typ := q.Get("type")
+		return func(s *lg.Site) bool { return typ == "" || s.Type == typ }
+	},
+	options: func(w *lg.World) map[string][]string {
+		return map[string][]string{"type": distinct(w.Sites, func(s *lg.Site) string { return s.Type })}
+	},
+}
+
+// coordsKey sorts "x,y" coordinates by x then y.
+func coordsKey(coords string) string {
+	x, y, _ := strings.Cut(coords, ",")
+	return fmt.Sprintf("%08s,%08s", x, y)
+}
+
+var writtenContentList = &list[*lg.WrittenContent]{
+	template: "literature",
+	records:  func(w *lg.World) []*lg.WrittenContent { return w.WrittenContents },
+	id:       func(c *lg.WrittenContent) int { return c.ID },
+	name:     func(_ *lg.World, c *lg.WrittenContent) string { return c.Title },
+	columns: []column[*lg.WrittenContent]{
+		{"id", "#", func(_ *lg.World, c *lg.WrittenContent) interface{} { return c.ID }},
+		{"title", "Title", func(_ *lg.World, c *lg.WrittenContent) interface{} { return c.Title }},
+		{"form", "Form", func(_ *lg.World, c *lg.WrittenContent) interface{} { return writtenContentForm(c) }},
+		{"author", "Author", func(w *lg.World, c *lg.WrittenContent) interface{} {
+			if f := w.Figure(c.AuthorFigureID); f != nil {
+				return f.Name
+			}
+			return ""
+		}},
+	},
+	filter: func(w *lg.World, q url.Values) func(*lg.WrittenContent) bool {
+		form := q.Get("form")
+		return func(c *lg.WrittenContent) bool { return form == "" || writtenContentForm(c) == form }
+	},
+	options: func(w *lg.World) map[string][]string {
+		return map[string][]string{"form": distinct(w.WrittenContents, writtenContentForm)}
+	},
+}
+
+// writtenContentForm is the type legends_plus.xml gives a written content
+// or the form legends.xml does.
+func writtenContentForm(c *lg.WrittenContent) string {
+	if c.Type != "" {
+		return c.Type
+	}
+	return c.Form
+}
@@ -0,0 +1,91 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+// relationships is the data of the relationships template: the relationships
+// of Figure and, if ?to is set, the shortest Path from it to figure To.
+type relationships struct {
+	World         *lg.World
+	Figure        *lg.Figure
+	Relationships []*lg.Relationship
+	To            *lg.Figure
+	Path          []*lg.Relationship
+}
+
+// relationshipsHandler serves the relationships page of fig from world.
+func (s *Server) relationshipsHandler(w http.ResponseWriter, r *http.Request, world *lg.World, fig *lg.Figure) {
+	data := relationships{World: world, Figure: fig, Relationships: world.Relationships(fig.ID)}
+	if id, err := strconv.Atoi(r.URL.Query().Get("to")); err == nil {
+		data.To = world.Figure(id)
+		data.Path = world.SocialPath(fig.ID, id)
+	}
+	s.execute(w, world, "relationships", data)
+}
+
+// relationshipsAPIHandler serves the relationships of the figure ?hfid.
+func (s *Server) relationshipsAPIHandler(w http.ResponseWriter, r *http.Request) {
+	world := s.current(r).World
+	id, err := figureParam(world, r.URL.Query(), "hfid")
+	if err != nil {
+		writeJSON(w, 400, map[string]string{"error": err.Error()})
+		return
+	}
+	rels := world.Relationships(id)
+	if rels == nil {
+		rels = []*lg.Relationship{}
+	}
+	writeJSON(w, 200, rels)
+}
+
+// socialPathHandler serves the shortest chain of relationships from the
+// figure ?from to the figure ?to or 404 if they aren't connected.
+func (s *Server) socialPathHandler(w http.ResponseWriter, r *http.Request) {
+	world := s.current(r).World
+	q := r.URL.Query()
+	from, err := figureParam(world, q, "from")
+	if err != nil {
+		writeJSON(w, 400, map[string]string{"error": err.Error()})
+		return
+	}
+	to, err := figureParam(world, q, "to")
+	if err != nil {
+		writeJSON(w, 400, map[string]string{"error": err.Error()})
+		return
+	}
+	path := world.SocialPath(from, to)
+	if path == nil && from != to {
+		writeJSON(w, 404, map[string]string{"error": fmt.Sprintf("figures %d and %d aren't connected", from, to)})
+		return
+	}
+	if path == nil {
+		path = []*lg.Relationship{}
+	}
+	writeJSON(w, 200, path)
+}
+
+// figureParam returns the ID of the figure in the query parameter name.
+func figureParam(world *lg.World, q url.Values, name string) (int, error) {
+	id, err := strconv.Atoi(q.Get(name))
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a figure id", name)
+	}
+	if world.Figure(id) == nil {
+		return 0, fmt.Errorf("figure %d not found", id)
+	}
+	return id, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+// searchLimit is the most results of each kind the search page shows.
+const searchLimit = 100
+
+// search is the data of the search page: records whose names contain Query.
+type search struct {
+	World           *lg.World
+	Query           string
+	Figures         []*lg.Figure
+	Sites           []*lg.Site
+	Entities        []*lg.Entity
+	Artifacts       []*lg.Artifact
+	WrittenContents []*lg.WrittenContent
+}
+
+func newSearch(w *lg.World, q string) *search {
+	sr := &search{World: w, Query: q}
+	q = strings.ToLower(strings.TrimSpace(q))
+	if q == "" {
+		return sr
+	}
+	match := func(name string) bool { return strings.Contains(strings.ToLower(name), q) }
+	for _, f := range w.Figures {
+		if len(sr.Figures) < searchLimit && match(f.Name) {
+			sr.Figures = append(sr.Figures, f)
+		}
+	}
+	for _, s := range w.Sites {
+		if len(sr.Sites) < searchLimit && match(s.Name) {
+			sr.Sites = append(sr.Sites, s)
+		}
+	}
+	for _, e := range w.Entities {
+		if len(sr.Entities) < searchLimit && e.Name != "" && match(e.Name) {
+			sr.Entities = append(sr.Entities, e)
+		}
+	}
+	for _, a := range w.Artifacts {
+		if len(sr.Artifacts) < searchLimit && match(a.Name) {
+			sr.Artifacts = append(sr.Artifacts, a)
+		}
+	}
+	for _, c := range w.WrittenContents {
+		if len(sr.WrittenContents) < searchLimit && match(c.Title) {
+			sr.WrittenContents = append(sr.WrittenContents, c)
+		}
+	}
+	return sr
+}
+
+func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	world := s.current(r).World
+	s.execute(w, world, "search", newSearch(world, r.URL.Query().Get("q")))
+}
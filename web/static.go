@@ -0,0 +1,151 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// started is the modification time of the built in static files since
+// embedded files don't have one.
+var started = time.Now()
+
+// contentTypes are the types of extensions mime doesn't know on every system.
+var contentTypes = map[string]string{
+	".bmp":   "image/bmp",
+	".ico":   "image/x-icon",
+	".svg":   "image/svg+xml",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+}
+
+// staticFile is a static file ready to serve: its ETag and, if it's worth
+// compressing, its gzipped contents.
+type staticFile struct {
+	data    []byte
+	gz      []byte
+	etag    string
+	modTime time.Time
+}
+
+func newStaticFile(name string, data []byte, modTime time.Time) *staticFile {
+	sum := sha256.Sum256(data)
+	f := &staticFile{data: data, etag: fmt.Sprintf(`"%x"`, sum[:8]), modTime: modTime}
+	if compressible(contentType(name)) {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		zw.Write(data)
+		zw.Close()
+		if buf.Len() < len(data) {
+			f.gz = buf.Bytes()
+		}
+	}
+	return f
+}
+
+// newStaticFiles prepares the built in static files: everything in assets
+// except the templates.
+func newStaticFiles() (map[string]*staticFile, error) {
+	files := map[string]*staticFile{}
+	err := fs.WalkDir(assets, "assets", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == "assets/templates" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		data, err := assets.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(p, "assets/")
+		files[name] = newStaticFile(name, data, started)
+		return nil
+	})
+	return files, err
+}
+
+// staticHandler serves the files under /assets/ from Templates or the built
+// in ones with caching headers, gzipped if the client accepts it.
+func (s *Server) staticHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/assets/")
+	f, err := s.staticFile(name)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("error loading asset %q: %v", name, err)
+		}
+		http.NotFound(w, r)
+		return
+	}
+	h := w.Header()
+	h.Set("Content-Type", contentType(name))
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Vary", "Accept-Encoding")
+	data, etag := f.data, f.etag
+	if f.gz != nil && acceptsGzip(r) {
+		h.Set("Content-Encoding", "gzip")
+		data, etag = f.gz, strings.TrimSuffix(etag, `"`)+`-gzip"`
+	}
+	h.Set("ETag", etag)
+	http.ServeContent(w, r, name, f.modTime, bytes.NewReader(data))
+}
+
+// staticFile returns the static file name from Templates or the built in
+// one. Templates in the root of Templates aren't static files.
+func (s *Server) staticFile(name string) (*staticFile, error) {
+	if s.opts.Templates != nil && !(path.Dir(name) == "." && path.Ext(name) == ".html") {
+		data, err := fs.ReadFile(s.opts.Templates, name)
+		if err == nil {
+			mod := started
+			if fi, err := fs.Stat(s.opts.Templates, name); err == nil {
+				mod = fi.ModTime()
+			}
+			return newStaticFile(name, data, mod), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if f := s.static[name]; f != nil {
+		return f, nil
+	}
+	return nil, fs.ErrNotExist
+}
+
+func contentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ct, ok := contentTypes[ext]; ok {
+		return ct
+	}
+	if ct := mime.TypeByExtension(ext); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+func compressible(ct string) bool {
+	return strings.HasPrefix(ct, "text/") || strings.Contains(ct, "javascript") ||
+		strings.Contains(ct, "json") || strings.Contains(ct, "xml")
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.TrimSpace(name) == "gzip" && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,607 @@
+// Package web serves a World over HTTP: server side rendered pages and a
+// read-only json API. Server is an http.Handler so it can be mounted in
+// other programs.
+package web
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schmichael/legendarygopher/lg"
+)
+
+// assets are the built in templates and static files.
+//
+//go:embed assets
+var assets embed.FS
+
+// templateNames are the pages a Server renders. They're loaded from
+// assets/templates/{name}.html with the partials in layout.html unless
+// overridden.
+var templateNames = []string{
+	"index", "artifacts", "entities", "entity", "events", "figures", "figure",
+	"sites", "site", "literature", "writtencontent", "stats", "form", "map",
+	"search", "pantheons", "relationships", "connect", "loading",
+}
+
+// Options configure a Server.
+type Options struct {
+	// Prefix is the path the Server is mounted at, e.g. "/legends". Links in
+	// pages include it and it's stripped from requests.
+	Prefix string
+
+	// Templates overrides the built in templates with the files named
+	// {name}.html in it, e.g. index.html, and the stylesheet with
+	// css/main.css. Files it doesn't contain are built in. See TEMPLATES.md
+	// for the data passed to each template.
+	Templates fs.FS
+
+	// Dev reparses templates on every request so changes to Templates show
+	// up without restarting.
+	Dev bool
+
+	// APIOnly serves only the json API under /api/.
+	APIOnly bool
+}
+
+// File is a file of the export a World was loaded from.
+type File struct {
+	Name string
+	Size int64
+	Open func() (io.ReadCloser, error)
+}
+
+// Reload is a summary of reloading the World shown on the index page.
+type Reload struct {
+	Time     time.Time
+	File     string
+	Duration time.Duration
+	Changes  []*SectionChange
+
+	// Error is set if the reload failed and the old World is still served
+	Error string
+}
+
+// SectionChange is the number of records in a section before and after a
+// reload.
+type SectionChange struct {
+	Section string
+	Before  int
+	After   int
+}
+
+func (c *SectionChange) Diff() int { return c.After - c.Before }
+
+// Loaded is a World and the files it was loaded from. It's replaced as a
+// whole when the World is reloaded.
+type Loaded struct {
+	World *lg.World
+
+	// Files in the archive the world was loaded from and map images
+	Files []*File
+	Maps  []*File
+
+	// Closer closes the archives Files are in if it's not nil. It's called
+	// once a reload replaces it and the requests using it are done. It must
+	// be comparable, e.g. a pointer.
+	Closer io.Closer
+
+	// Reload is the last reload or nil if the World hasn't been reloaded
+	Reload *Reload
+}
+
+// loadedKey is the request context key of the Loaded a request uses.
+type loadedKey struct{}
+
+// Status is a snapshot of the progress of loading a World.
+type Status struct {
+	File     string         `json:"file"`
+	Read     int64          `json:"bytes_read"`
+	Size     int64          `json:"bytes_total"`
+	Percent  int            `json:"percent"`
+	Sections map[string]int `json:"sections"`
+	Records  int            `json:"records"`
+	Elapsed  float64        `json:"elapsed_seconds"`
+	ETA      float64        `json:"eta_seconds"`
+	Ready    bool           `json:"ready"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// Progress of loading a World shown until it's set.
+type Progress interface {
+	Status() Status
+
+	// Wait returns a channel which is closed when progress is made.
+	Wait() <-chan struct{}
+}
+
+// loadedProgress is the Progress of a World which was loaded before the
+// Server was created.
+type loadedProgress struct{}
+
+func (loadedProgress) Status() Status        { return Status{Ready: true} }
+func (loadedProgress) Wait() <-chan struct{} { return nil }
+
+// Server serves a World over HTTP.
+type Server struct {
+	opts      Options
+	mux       *http.ServeMux
+	handler   http.Handler
+	templates *template.Template
+	static    map[string]*staticFile
+
+	// mu protects cur which is swapped when the World is reloaded
+	mu  sync.RWMutex
+	cur *Loaded
+
+	// refs counts the requests using each Closer; it's protected by mu
+	refs map[io.Closer]int
+
+	// progress of loading the World; loaded is closed once it's set
+	progress Progress
+	loaded   chan struct{}
+}
+
+// New returns a Server for w.
+func New(w *lg.World, opts Options) (*Server, error) {
+	s, err := NewLoading(loadedProgress{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.SetWorld(&Loaded{World: w})
+	return s, nil
+}
+
+// NewLoading returns a Server which shows the loading page with p until
+// SetWorld is called.
+func NewLoading(p Progress, opts Options) (*Server, error) {
+	opts.Prefix = strings.TrimRight(opts.Prefix, "/")
+	s := &Server{
+		opts:     opts,
+		mux:      http.NewServeMux(),
+		refs:     map[io.Closer]int{},
+		progress: p,
+		loaded:   make(chan struct{}),
+	}
+	var err error
+	if s.templates, err = s.parseTemplates(); err != nil {
+		return nil, err
+	}
+	if s.static, err = newStaticFiles(); err != nil {
+		return nil, err
+	}
+	s.handler = http.StripPrefix(opts.Prefix, s.mux)
+	s.routes()
+	return s, nil
+}
+
+// parseTemplates parses the layout partials and every page into one
+// template set. Each file is read from Templates or the built in assets.
+func (s *Server) parseTemplates() (*template.Template, error) {
+	// funcs are bound to the World of each request by templateFor
+	set := template.New("").Funcs(s.funcs(&lg.World{}))
+	for _, name := range append([]string{"layout"}, templateNames...) {
+		src, err := s.templateSource(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := set.New(name).Parse(string(src)); err != nil {
+			return nil, fmt.Errorf("error parsing template %s: %v", name, err)
+		}
+	}
+	return set, nil
+}
+
+func (s *Server) templateSource(name string) ([]byte, error) {
+	if s.opts.Templates != nil {
+		b, err := fs.ReadFile(s.opts.Templates, name+".html")
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return b, err
+		}
+	}
+	return fs.ReadFile(assets, "assets/templates/"+name+".html")
+}
+
+// template returns the template set, reparsing it in dev mode.
+func (s *Server) template() (*template.Template, error) {
+	if s.opts.Dev {
+		return s.parseTemplates()
+	}
+	return s.templates, nil
+}
+
+// templateFor returns a copy of the template set with funcs looking records
+// up in w, so a request uses one World even if it's reloaded during it.
+func (s *Server) templateFor(w *lg.World) (*template.Template, error) {
+	t, err := s.template()
+	if err != nil {
+		return nil, err
+	}
+	if t, err = t.Clone(); err != nil {
+		return nil, err
+	}
+	return t.Funcs(s.funcs(w)), nil
+}
+
+// SetWorld sets the loaded World and starts serving it. The Closer of the
+// Loaded it replaces is called once the requests using it are done unless
+// l shares it.
+func (s *Server) SetWorld(l *Loaded) {
+	s.mu.Lock()
+	old := s.cur
+	s.cur = l
+	done := old != nil && old.Closer != nil && old.Closer != l.Closer && s.refs[old.Closer] == 0
+	s.mu.Unlock()
+	if old == nil {
+		close(s.loaded)
+	}
+	if done {
+		closeFiles(old.Closer)
+	}
+}
+
+// Current returns the World being served or nil if it's still loading.
+func (s *Server) Current() *Loaded {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cur
+}
+
+// current returns the Loaded a request uses for its whole duration even if
+// the World is reloaded during it.
+func (s *Server) current(r *http.Request) *Loaded {
+	if l, ok := r.Context().Value(loadedKey{}).(*Loaded); ok {
+		return l
+	}
+	return s.Current()
+}
+
+// acquire returns the Loaded being served and keeps its files open until
+// it's released.
+func (s *Server) acquire() *Loaded {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur.Closer != nil {
+		s.refs[s.cur.Closer]++
+	}
+	return s.cur
+}
+
+// release closes the files of l if it's been replaced and was the last
+// request using them.
+func (s *Server) release(l *Loaded) {
+	if l.Closer == nil {
+		return
+	}
+	s.mu.Lock()
+	s.refs[l.Closer]--
+	done := s.refs[l.Closer] == 0 && s.cur.Closer != l.Closer
+	if s.refs[l.Closer] == 0 {
+		delete(s.refs, l.Closer)
+	}
+	s.mu.Unlock()
+	if done {
+		closeFiles(l.Closer)
+	}
+}
+
+func closeFiles(c io.Closer) {
+	if err := c.Close(); err != nil {
+		log.Printf("error closing the files of a replaced world: %v", err)
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Prefix != "" && r.URL.Path == s.opts.Prefix {
+		http.Redirect(w, r, s.opts.Prefix+"/", http.StatusMovedPermanently)
+		return
+	}
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() {
+	// Serverside rendered html
+	if !s.opts.APIOnly {
+		s.mux.HandleFunc("/", s.ready(s.listHandler("index")))
+		s.mux.HandleFunc("/artifacts", s.ready(serveList(s, artifactList)))
+		s.mux.HandleFunc("/entities", s.ready(serveList(s, entityList)))
+		s.mux.HandleFunc("/entities/", s.ready(s.entityHandler))
+		s.mux.HandleFunc("/events", s.ready(serveList(s, eventList)))
+		s.mux.HandleFunc("/figures", s.ready(serveList(s, figureList)))
+		s.mux.HandleFunc("/figures/", s.ready(s.figureHandler))
+		s.mux.HandleFunc("/sites", s.ready(serveList(s, siteList)))
+		s.mux.HandleFunc("/sites/", s.ready(s.siteHandler))
+		s.mux.HandleFunc("/stats", s.ready(s.listHandler("stats")))
+		s.mux.HandleFunc("/pantheons", s.ready(s.listHandler("pantheons")))
+		s.mux.HandleFunc("/literature", s.ready(serveList(s, writtenContentList)))
+		s.mux.HandleFunc("/writtencontents/", s.ready(s.writtenContentHandler))
+		s.mux.HandleFunc("/forms/", s.ready(s.formHandler))
+		s.mux.HandleFunc("/map", s.ready(s.listHandler("map")))
+		s.mux.HandleFunc("/search", s.ready(s.searchHandler))
+		s.mux.HandleFunc("/connect", s.ready(s.connectHandler))
+		s.mux.HandleFunc("/maps/", s.ready(s.mapHandler))
+		s.mux.HandleFunc("/assets/", s.staticHandler)
+	}
+
+	// API
+	s.mux.HandleFunc("/api/status", s.statusHandler)
+	s.mux.HandleFunc("/api/world", s.ready(s.jsonify(func(w *lg.World) interface{} { return w })))
+	s.mux.HandleFunc("/api/artifacts", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.Artifacts })))
+	s.mux.HandleFunc("/api/entities", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.Entities })))
+	s.mux.HandleFunc("/api/events", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.Events })))
+	s.mux.HandleFunc("/api/figures", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.Figures })))
+	s.mux.HandleFunc("/api/sites", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.Sites })))
+	s.mux.HandleFunc("/api/regions", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.Regions })))
+	s.mux.HandleFunc("/api/undergroundregions", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.UndergroundRegions })))
+	s.mux.HandleFunc("/api/writtencontents", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.WrittenContents })))
+	s.mux.HandleFunc("/api/poeticforms", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.PoeticForms })))
+	s.mux.HandleFunc("/api/musicalforms", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.MusicalForms })))
+	s.mux.HandleFunc("/api/danceforms", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.DanceForms })))
+	s.mux.HandleFunc("/api/relationships", s.ready(s.relationshipsAPIHandler))
+	s.mux.HandleFunc("/api/socialpath", s.ready(s.socialPathHandler))
+	s.mux.HandleFunc("/api/connect", s.ready(s.connectAPIHandler))
+	s.mux.HandleFunc("/api/validate", s.ready(s.jsonify(func(w *lg.World) interface{} { return w.Validate() })))
+}
+
+// ready serves the loading page (or a 503 for the API) until the World is
+// loaded.
+func (s *Server) ready(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-s.loaded:
+			cur := s.acquire()
+			defer s.release(cur)
+			f(w, r.WithContext(context.WithValue(r.Context(), loadedKey{}, cur)))
+			return
+		default:
+		}
+		w.Header().Set("Retry-After", "1")
+		if s.opts.APIOnly || strings.HasPrefix(r.URL.Path, "/api/") {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(503)
+			json.NewEncoder(w).Encode(s.progress.Status())
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(503)
+		t, err := s.templateFor(&lg.World{})
+		if err == nil {
+			err = t.ExecuteTemplate(w, "loading", s.progress.Status())
+		}
+		if err != nil {
+			log.Printf("error executing template loading: %v", err)
+		}
+	}
+}
+
+// statusHandler streams the loading progress as server-sent events until
+// the World is loaded or returns it as json if the client doesn't accept
+// event streams.
+func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok || !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.progress.Status())
+		return
+	}
+	// The stream lasts until the World is loaded; don't let the write
+	// timeout cut it off
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	for {
+		changed := s.progress.Wait()
+		st := s.progress.Status()
+		b, err := json.Marshal(st)
+		if err != nil {
+			log.Printf("error encoding status: %v", err)
+			return
+		}
+		fmt.Fprintf(w, "data: %s\n\n", b)
+		flusher.Flush()
+		if st.Ready || st.Error != "" {
+			return
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) listHandler(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cur := s.current(r)
+		s.execute(w, cur.World, name, cur)
+	}
+}
+
+func (s *Server) figureHandler(w http.ResponseWriter, r *http.Request) {
+	cur := s.current(r)
+	id := 0
+	if _, err := fmt.Sscanf(r.URL.Path, "/figures/%d", &id); err != nil {
+		log.Printf("error getting figure id from %q: %v", r.URL.Path, err)
+		w.WriteHeader(404)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	fig := cur.World.Figure(id)
+	if fig == nil {
+		w.WriteHeader(404)
+		fmt.Fprintf(w, "not found: figure %d", id)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/relationships") {
+		s.relationshipsHandler(w, r, cur.World, fig)
+		return
+	}
+	context := struct {
+		Figure *lg.Figure
+		World  *lg.World
+		Eras   []*lg.EraEvents
+	}{fig, cur.World, cur.World.ByEra(cur.World.EventsForFigure(id))}
+	s.execute(w, cur.World, "figure", context)
+}
+
+func (s *Server) siteHandler(w http.ResponseWriter, r *http.Request) {
+	cur := s.current(r)
+	id := 0
+	if _, err := fmt.Sscanf(r.URL.Path, "/sites/%d", &id); err != nil {
+		log.Printf("error getting site id from %q: %v", r.URL.Path, err)
+		w.WriteHeader(404)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	site := cur.World.Site(id)
+	if site == nil {
+		w.WriteHeader(404)
+		fmt.Fprintf(w, "not found: site %d", id)
+		return
+	}
+	context := struct {
+		Site  *lg.Site
+		World *lg.World
+		Eras  []*lg.EraEvents
+	}{site, cur.World, cur.World.ByEra(cur.World.EventsForSite(id))}
+	s.execute(w, cur.World, "site", context)
+}
+
+func (s *Server) entityHandler(w http.ResponseWriter, r *http.Request) {
+	cur := s.current(r)
+	id := 0
+	if _, err := fmt.Sscanf(r.URL.Path, "/entities/%d", &id); err != nil {
+		log.Printf("error getting entity id from %q: %v", r.URL.Path, err)
+		w.WriteHeader(404)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	e := cur.World.Entity(id)
+	if e == nil {
+		w.WriteHeader(404)
+		fmt.Fprintf(w, "not found: entity %d", id)
+		return
+	}
+	context := struct {
+		Entity *lg.Entity
+		World  *lg.World
+	}{e, cur.World}
+	s.execute(w, cur.World, "entity", context)
+}
+
+func (s *Server) writtenContentHandler(w http.ResponseWriter, r *http.Request) {
+	cur := s.current(r)
+	id := 0
+	if _, err := fmt.Sscanf(r.URL.Path, "/writtencontents/%d", &id); err != nil {
+		log.Printf("error getting written content id from %q: %v", r.URL.Path, err)
+		w.WriteHeader(404)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	c := cur.World.WrittenContent(id)
+	if c == nil {
+		w.WriteHeader(404)
+		fmt.Fprintf(w, "not found: written content %d", id)
+		return
+	}
+	context := struct {
+		WrittenContent *lg.WrittenContent
+		World          *lg.World
+	}{c, cur.World}
+	s.execute(w, cur.World, "writtencontent", context)
+}
+
+func (s *Server) formHandler(w http.ResponseWriter, r *http.Request) {
+	cur := s.current(r)
+	kind, id := "", 0
+	if _, err := fmt.Sscanf(strings.Replace(r.URL.Path, "/", " ", -1), " forms %s %d", &kind, &id); err != nil {
+		log.Printf("error getting form from %q: %v", r.URL.Path, err)
+		w.WriteHeader(404)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	var form *lg.Form
+	var contents []*lg.WrittenContent
+	switch kind {
+	case "poetic":
+		form, contents = cur.World.PoeticForm(id), cur.World.FormWrittenContents("poem", id)
+	case "musical":
+		form, contents = cur.World.MusicalForm(id), cur.World.FormWrittenContents("musical composition", id)
+	case "dance":
+		form, contents = cur.World.DanceForm(id), cur.World.FormWrittenContents("choreography", id)
+	}
+	if form == nil {
+		w.WriteHeader(404)
+		fmt.Fprintf(w, "not found: %s form %d", kind, id)
+		return
+	}
+	context := struct {
+		Kind            string
+		Form            *lg.Form
+		WrittenContents []*lg.WrittenContent
+		World           *lg.World
+	}{kind, form, contents, cur.World}
+	s.execute(w, cur.World, "form", context)
+}
+
+func (s *Server) execute(w http.ResponseWriter, world *lg.World, name string, data interface{}) {
+	t, err := s.templateFor(world)
+	if err == nil {
+		err = t.ExecuteTemplate(w, name, data)
+	}
+	if err != nil {
+		log.Printf("error executing template %s: %v", name, err)
+		w.WriteHeader(500)
+		w.Write([]byte(err.Error()))
+	}
+}
+
+// mapHandler serves the map image at an index of Maps.
+func (s *Server) mapHandler(w http.ResponseWriter, r *http.Request) {
+	cur := s.current(r)
+	i := 0
+	if _, err := fmt.Sscanf(r.URL.Path, "/maps/%d", &i); err != nil || i < 0 || i >= len(cur.Maps) {
+		w.WriteHeader(404)
+		fmt.Fprintf(w, "not found: map %q", r.URL.Path)
+		return
+	}
+	m := cur.Maps[i]
+	f, err := m.Open()
+	if err != nil {
+		log.Printf("error opening map %q: %v", m.Name, err)
+		w.WriteHeader(500)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", contentType(m.Name))
+	w.Header().Set("Content-Length", fmt.Sprint(m.Size))
+	if _, err := io.Copy(w, f); err != nil {
+		log.Printf("error sending map %q: %v", m.Name, err)
+	}
+}
+
+func (s *Server) jsonify(f func(w *lg.World) interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collection := f(s.current(r).World)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if err := json.NewEncoder(w).Encode(collection); err != nil {
+			w.WriteHeader(500)
+			log.Printf("error encoding json for %q %T: %v", r.URL.Path, collection, err)
+			return
+		}
+	}
+}